@@ -0,0 +1,164 @@
+package gostree
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const binaryFormatVersion byte = 1
+
+// Node flags in the binary format
+const (
+	flagBlack byte = 1 << iota
+	flagHasLeft
+	flagHasRight
+)
+
+// MarshalBinary encodes the tree, preserving its exact structure, so that
+// UnmarshalBinary can reconstruct it in O(n) without comparisons or rebalancing.
+// Nodes are written in level order with their color, subtree size and key,
+// which is encoded with the function set by WithCodec.
+//
+// The format is:
+//
+//	version byte
+//	uvarint node count
+//	per node: flags byte, uvarint size, uvarint key length, key bytes
+func (t *Tree[T]) MarshalBinary() ([]byte, error) {
+	if t.encodeKey == nil {
+		return nil, ErrNoCodec
+	}
+
+	buf := []byte{binaryFormatVersion}
+	buf = binary.AppendUvarint(buf, uint64(t.root.size))
+	if t.root == t.nil {
+		return buf, nil
+	}
+
+	queue := make([]*Node[T], 0, t.root.size)
+	queue = append(queue, t.root)
+	for i := 0; i < len(queue); i++ {
+		node := queue[i]
+
+		var flags byte
+		if node.color == BLACK {
+			flags |= flagBlack
+		}
+		if node.left != t.nil {
+			flags |= flagHasLeft
+			queue = append(queue, node.left)
+		}
+		if node.right != t.nil {
+			flags |= flagHasRight
+			queue = append(queue, node.right)
+		}
+
+		key := t.encodeKey(node.key)
+		buf = append(buf, flags)
+		buf = binary.AppendUvarint(buf, uint64(node.size))
+		buf = binary.AppendUvarint(buf, uint64(len(key)))
+		buf = append(buf, key...)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the contents of the tree with the tree encoded in data
+// by MarshalBinary, decoding keys with the function set by WithCodec.
+// The comparator and options of the receiver are kept.
+// On error the tree is left unchanged.
+func (t *Tree[T]) UnmarshalBinary(data []byte) error {
+	if t.decodeKey == nil {
+		return ErrNoCodec
+	}
+	if len(data) == 0 || data[0] != binaryFormatVersion {
+		return fmt.Errorf("%w: unsupported format version", ErrCorruptData)
+	}
+	data = data[1:]
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(len(data)-n)/3 { // every node takes at least 3 bytes
+		return fmt.Errorf("%w: invalid node count", ErrCorruptData)
+	}
+	data = data[n:]
+
+	type slot struct {
+		parent *Node[T]
+		isLeft bool
+	}
+
+	root := t.nil
+	slots := []slot{{parent: t.nil, isLeft: false}}
+	for i := uint64(0); i < count; i++ {
+		if len(slots) == 0 || len(data) == 0 {
+			return fmt.Errorf("%w: unexpected end of structure", ErrCorruptData)
+		}
+		flags := data[0]
+		data = data[1:]
+
+		size, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("%w: invalid subtree size", ErrCorruptData)
+		}
+		data = data[n:]
+
+		keyLen, n := binary.Uvarint(data)
+		if n <= 0 || keyLen > uint64(len(data)-n) {
+			return fmt.Errorf("%w: invalid key length", ErrCorruptData)
+		}
+		data = data[n:]
+		key, err := t.decodeKey(data[:keyLen])
+		if err != nil {
+			return fmt.Errorf("%w: decoding key: %w", ErrCorruptData, err)
+		}
+		data = data[keyLen:]
+
+		color := RED
+		if flags&flagBlack != 0 {
+			color = BLACK
+		}
+		s := slots[0]
+		slots = slots[1:]
+		node := &Node[T]{
+			key:    key,
+			left:   t.nil,
+			right:  t.nil,
+			parent: s.parent,
+			color:  color,
+			size:   int(size),
+		}
+		switch {
+		case s.parent == t.nil:
+			root = node
+		case s.isLeft:
+			s.parent.left = node
+		default:
+			s.parent.right = node
+		}
+
+		if flags&flagHasLeft != 0 {
+			slots = append(slots, slot{parent: node, isLeft: true})
+		}
+		if flags&flagHasRight != 0 {
+			slots = append(slots, slot{parent: node, isLeft: false})
+		}
+	}
+	if len(slots) != 0 && count > 0 || len(data) != 0 {
+		return fmt.Errorf("%w: structure does not match node count", ErrCorruptData)
+	}
+
+	if root != t.nil && uint64(root.size) != count {
+		return fmt.Errorf("%w: root size does not match node count", ErrCorruptData)
+	}
+	// Decoded data must satisfy every invariant the tree relies on, including
+	// colors and key order, or searches on the result would silently misbehave
+	if err := t.validateFrom(root); err != nil {
+		return fmt.Errorf("%w: %w", ErrCorruptData, err)
+	}
+
+	t.removeAll()
+	t.root = root
+	t.offered = root.size
+
+	return nil
+}
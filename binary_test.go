@@ -0,0 +1,167 @@
+package gostree
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func intCodec() Option[int] {
+	return WithCodec(
+		func(v int) []byte { return binary.AppendVarint(nil, int64(v)) },
+		func(b []byte) (int, error) {
+			v, n := binary.Varint(b)
+			if n != len(b) {
+				return 0, errors.New("bad varint")
+			}
+
+			return int(v), nil
+		},
+	)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		key   int
+		color string
+		size  int
+		depth int
+	}
+
+	layout := func(tree *Tree[int]) []node {
+		var nodes []node
+		tree.WalkNodes(func(key int, color string, size int, depth int) bool {
+			nodes = append(nodes, node{key: key, color: color, size: size, depth: depth})
+
+			return true
+		})
+
+		return nodes
+	}
+
+	testCases := []struct {
+		name   string
+		values []int
+	}{
+		{name: "empty", values: nil},
+		{name: "single", values: []int{7}},
+		{name: "duplicates", values: []int{5, 5, 5, 1, 9, 5}},
+		{name: "ascending", values: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}},
+		{name: "mixed", values: []int{50, 20, 80, -10, 30, 70, 90, 25, 35, 65, 0}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			src := NewTree[int](func(a, b int) int { return a - b }, intCodec())
+			for _, v := range tc.values {
+				src.Insert(v)
+			}
+
+			data, err := src.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+
+			dst := NewTree[int](func(a, b int) int { return a - b }, intCodec())
+			dst.Insert(1000)
+			if err := dst.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+
+			want, got := layout(src), layout(dst)
+			if len(got) != len(want) {
+				t.Fatalf("got %d nodes, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("node %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+			checkRedBlackProperties(t, dst)
+			verifySizes(t, dst.root, dst.nil)
+
+			dst.Insert(3)
+			if !dst.Search(3) {
+				t.Error("decoded tree should accept inserts")
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no_codec", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, err := tree.MarshalBinary(); !errors.Is(err, ErrNoCodec) {
+			t.Errorf("MarshalBinary() error = %v, want ErrNoCodec", err)
+		}
+		if err := tree.UnmarshalBinary([]byte{binaryFormatVersion, 0}); !errors.Is(err, ErrNoCodec) {
+			t.Errorf("UnmarshalBinary() error = %v, want ErrNoCodec", err)
+		}
+	})
+
+	src := NewTree[int](func(a, b int) int { return a - b }, intCodec())
+	for _, v := range []int{4, 2, 6, 1, 3} {
+		src.Insert(v)
+	}
+	valid, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	// Every node of src takes 4 bytes: flags, size, key length and a 1-byte key.
+	// In level order the nodes are 4, 2, 6, 1 and 3, starting at offset 2
+	corrupt := func(offset int, f func(byte) byte) []byte {
+		data := append([]byte(nil), valid...)
+		data[offset] = f(data[offset])
+
+		return data
+	}
+	badSize := corrupt(3, func(b byte) byte { return b + 1 }) // size of the root
+	redRoot := corrupt(2, func(b byte) byte { return b &^ flagBlack })
+	redRed := corrupt(6, func(b byte) byte { return b &^ flagBlack })                          // node 2 above red 1 and 3
+	blackHeight := corrupt(10, func(b byte) byte { return b &^ flagBlack })                    // node 6 only
+	outOfOrder := corrupt(5, func(byte) byte { return byte(binary.AppendVarint(nil, -1)[0]) }) // root key 4 becomes -1
+	hugeCount := append([]byte{binaryFormatVersion}, binary.AppendUvarint(nil, uint64(len(valid)/2))...)
+	hugeCount = append(hugeCount, valid[2:]...)
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty_input", data: nil},
+		{name: "wrong_version", data: append([]byte{99}, valid[1:]...)},
+		{name: "truncated", data: valid[:len(valid)-2]},
+		{name: "trailing_bytes", data: append(append([]byte(nil), valid...), 0)},
+		{name: "inconsistent_size", data: badSize},
+		{name: "red_root", data: redRoot},
+		{name: "red_node_with_red_child", data: redRed},
+		{name: "unequal_black_heights", data: blackHeight},
+		{name: "keys_out_of_order", data: outOfOrder},
+		{name: "count_exceeds_data", data: hugeCount},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := NewTree[int](func(a, b int) int { return a - b }, intCodec())
+			tree.Insert(42)
+			if err := tree.UnmarshalBinary(tc.data); !errors.Is(err, ErrCorruptData) {
+				t.Errorf("UnmarshalBinary() error = %v, want ErrCorruptData", err)
+			}
+			if tree.Size() != 1 || !tree.Search(42) {
+				t.Error("tree should be unchanged after a failed UnmarshalBinary")
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package gostree
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// buildSorted replaces the contents of the tree with the given keys,
+// which must already be in ascending order according to the tree's comparator.
+//
+// The result is a perfectly balanced tree built in O(n) without any rotations.
+// All levels are BLACK except the deepest one, which is RED (unless it is the root),
+// so every path from the root to a leaf passes through the same number of BLACK nodes.
+//
+// A tree bounded with WithMaxSize keeps only the elements its eviction policy
+// would retain: the largest under EvictSmallest and the smallest under EvictLargest.
+func (t *Tree[T]) buildSorted(keys []T) {
+	t.removeAll()
+	if len(keys) == 0 {
+		return
+	}
+	if t.maxSize > 0 && len(keys) > t.maxSize {
+		switch t.evictPolicy {
+		case EvictSmallest:
+			keys = keys[len(keys)-t.maxSize:]
+		case EvictLargest:
+			keys = keys[:t.maxSize]
+		}
+	}
+	t.offered = len(keys)
+
+	redDepth := bits.Len(uint(len(keys))) - 1
+	t.root = t.buildSubtree(keys, t.nil, 0, redDepth)
+}
+
+// buildSubtree builds a balanced subtree from sorted keys and returns its root.
+// Both halves are at most half the input, so recursion depth is bounded by
+// log2(len(keys)), i.e. at most 64 frames even for the largest possible slice.
+func (t *Tree[T]) buildSubtree(keys []T, parent *Node[T], depth, redDepth int) *Node[T] {
+	if len(keys) == 0 {
+		return t.nil
+	}
+
+	mid := len(keys) / 2
+	color := BLACK
+	if depth == redDepth && depth > 0 {
+		color = RED
+	}
+
+	node := &Node[T]{
+		key:    keys[mid],
+		left:   t.nil,
+		right:  t.nil,
+		parent: parent,
+		color:  color,
+		size:   len(keys),
+	}
+	node.left = t.buildSubtree(keys[:mid], node, depth+1, redDepth)
+	node.right = t.buildSubtree(keys[mid+1:], node, depth+1, redDepth)
+
+	return node
+}
+
+// NewFromUnsorted creates a tree holding a copy of values in any order.
+// The values are stably sorted with compare and bulk-built into a balanced tree
+// in O(n log n), which is faster than inserting them one by one because no
+// rotations are needed. The values slice is not modified.
+func NewFromUnsorted[T any](values []T, compare CompareFunc[T], opts ...Option[T]) *Tree[T] {
+	t := NewTree(compare, opts...)
+	t.Reset(values)
+
+	return t
+}
+
+// NewSetFromSliceCounting creates a tree holding the distinct values of vals and
+// reports how many duplicates were dropped. Values that compare equal are
+// collapsed to the first of them in vals. The values are sorted in O(n log n)
+// and bulk-built into a balanced tree; vals is not modified.
+func NewSetFromSliceCounting[T any](vals []T, compare CompareFunc[T]) (*Tree[T], int) {
+	t := NewTree(compare)
+
+	keys := slices.Clone(vals)
+	slices.SortStableFunc(keys, compare)
+	keys = slices.CompactFunc(keys, func(a, b T) bool {
+		return compare(a, b) == 0
+	})
+	t.buildSorted(keys)
+
+	return t, len(vals) - len(keys)
+}
+
+// mergeSorted merges two ascending sequences into dst.
+// On ties, elements of a are placed before elements of b.
+func mergeSorted[T any](dst, a, b []T, compare CompareFunc[T]) []T {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if compare(b[j], a[i]) < 0 {
+			dst = append(dst, b[j])
+			j++
+		} else {
+			dst = append(dst, a[i])
+			i++
+		}
+	}
+	dst = append(dst, a[i:]...)
+	dst = append(dst, b[j:]...)
+
+	return dst
+}
+
+// Clone returns a structurally identical copy of the tree in O(n).
+// The copy shares the comparison function and options of the original
+// but no nodes, so either tree can be modified independently.
+//
+// The copy is made iteratively with an explicit stack, so it does not depend
+// on the call stack depth regardless of the tree's height.
+func (t *Tree[T]) Clone() *Tree[T] {
+	clone := t.emptyCopy()
+	clone.offered = t.offered
+	if t.root == t.nil {
+		return clone
+	}
+
+	type frame struct {
+		src    *Node[T] // node of the original tree to copy
+		parent *Node[T] // already copied parent in the clone
+		isLeft bool
+	}
+
+	stack := []frame{{src: t.root, parent: clone.nil, isLeft: false}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &Node[T]{
+			key:    top.src.key,
+			left:   clone.nil,
+			right:  clone.nil,
+			parent: top.parent,
+			color:  top.src.color,
+			size:   top.src.size,
+		}
+		switch {
+		case top.parent == clone.nil:
+			clone.root = node
+		case top.isLeft:
+			top.parent.left = node
+		default:
+			top.parent.right = node
+		}
+
+		if top.src.right != t.nil {
+			stack = append(stack, frame{src: top.src.right, parent: node, isLeft: false})
+		}
+		if top.src.left != t.nil {
+			stack = append(stack, frame{src: top.src.left, parent: node, isLeft: true})
+		}
+	}
+
+	return clone
+}
+
+// SubtreeByRank returns a new balanced tree holding the elements at ranks [i, j),
+// sharing the comparison function and options of t. Indices are clamped to
+// [0, Size()]; an empty or inverted range yields an empty tree. The elements are
+// collected by a rank-bounded walk in O(log n + (j - i)) and bulk-built without
+// rotations; t is not modified.
+func (t *Tree[T]) SubtreeByRank(i, j int) *Tree[T] {
+	i = max(i, 0)
+	j = min(j, t.root.size)
+
+	result := t.emptyCopy()
+	if i >= j {
+		return result
+	}
+
+	keys := make([]T, j-i)
+	t.Fill(keys, i)
+	result.buildSorted(keys)
+
+	return result
+}
+
+// emptyCopy returns an empty tree sharing the comparison function and options of t.
+// The options are applied anew, so counters such as WithMetrics start from zero
+// instead of being shared with t.
+func (t *Tree[T]) emptyCopy() *Tree[T] {
+	return NewTree(t.baseCompare, t.options...)
+}
@@ -0,0 +1,331 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuildSorted(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_input", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		tree.buildSorted(nil)
+
+		if tree.root != tree.nil {
+			t.Error("root does not point to sentinel after building from empty input")
+		}
+		if size := tree.Size(); size != 0 {
+			t.Errorf("Size() = %d, want 0", size)
+		}
+	})
+
+	t.Run("maintains_properties_for_all_sizes", func(t *testing.T) {
+		t.Parallel()
+
+		for n := 1; n <= 300; n++ {
+			keys := make([]int, n)
+			for i := range keys {
+				keys[i] = i
+			}
+
+			tree := NewTree[int](func(a, b int) int { return a - b })
+			tree.buildSorted(keys)
+
+			if size := tree.Size(); size != n {
+				t.Fatalf("n=%d: Size() = %d, want %d", n, size, n)
+			}
+
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+			verifyTreeIntegrity(t, tree)
+		}
+	})
+
+	t.Run("supports_mutation_after_build", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		tree.buildSorted([]int{1, 2, 2, 3, 5, 8, 13})
+
+		tree.Insert(4)
+		tree.Delete(2)
+		tree.Delete(13)
+
+		expected := []int{1, 2, 3, 4, 5, 8}
+		for i, want := range expected {
+			got, ok := tree.Select(i)
+			if !ok || got != want {
+				t.Errorf("Select(%d) = %d, %v; want %d, true", i, got, ok, want)
+			}
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		clone := buildTree(nil).Clone()
+		if clone.root != clone.nil || clone.Size() != 0 {
+			t.Error("clone of empty tree should be empty")
+		}
+
+		clone.Insert(1)
+		if clone.Size() != 1 {
+			t.Errorf("Size() = %d, want 1", clone.Size())
+		}
+	})
+
+	t.Run("copies_structure", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{50, 25, 75, 12, 37, 62, 87, 6, 18, 31, 43, 25})
+		tree.Delete(62)
+		clone := tree.Clone()
+
+		type visit struct {
+			key   int
+			color string
+			size  int
+			depth int
+		}
+		walk := func(tree *Tree[int]) []visit {
+			var visits []visit
+			tree.WalkNodes(func(key int, color string, size int, depth int) bool {
+				visits = append(visits, visit{key: key, color: color, size: size, depth: depth})
+
+				return true
+			})
+
+			return visits
+		}
+
+		if !slices.Equal(walk(tree), walk(clone)) {
+			t.Error("clone does not have the same structure as the original")
+		}
+
+		checkRedBlackProperties(t, clone)
+		verifySizes(t, clone.root, clone.nil)
+		verifyTreeIntegrity(t, clone)
+	})
+
+	t.Run("independent_of_original", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5})
+		clone := tree.Clone()
+
+		clone.Insert(6)
+		clone.Delete(1)
+		tree.Delete(3)
+
+		if got := tree.SortedSnapshot(); !slices.Equal(got, []int{1, 2, 4, 5}) {
+			t.Errorf("original elements = %v, want [1 2 4 5]", got)
+		}
+		if got := clone.SortedSnapshot(); !slices.Equal(got, []int{2, 3, 4, 5, 6}) {
+			t.Errorf("clone elements = %v, want [2 3 4 5 6]", got)
+		}
+
+		checkRedBlackProperties(t, tree)
+		checkRedBlackProperties(t, clone)
+	})
+
+	t.Run("large_tree", func(t *testing.T) {
+		t.Parallel()
+
+		if testing.Short() {
+			t.Skip("skipping large tree test in short mode")
+		}
+
+		const n = 1_000_000
+		keys := make([]int, n)
+		for i := range keys {
+			keys[i] = i
+		}
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		tree.buildSorted(keys)
+		tree.Insert(n) // grow one level past the perfectly balanced shape
+
+		clone := tree.Clone()
+
+		if size := clone.Size(); size != n+1 {
+			t.Errorf("Size() = %d, want %d", size, n+1)
+		}
+		for _, k := range []int{0, n / 2, n} {
+			if rank := clone.Rank(k); rank != k {
+				t.Errorf("Rank(%d) = %d, want %d", k, rank, k)
+			}
+		}
+
+		checkRedBlackProperties(t, clone)
+		verifySizes(t, clone.root, clone.nil)
+	})
+}
+
+func TestNewFromUnsorted(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		values []int
+	}{
+		{name: "empty", values: nil},
+		{name: "single", values: []int{1}},
+		{name: "reversed", values: []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{name: "duplicate_heavy", values: []int{3, 1, 3, 3, 2, 1, 3, 3, 1, 2, 3, 3, 3, 1, 2}},
+		{name: "all_equal", values: []int{7, 7, 7, 7, 7, 7, 7}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := slices.Clone(tc.values)
+			tree := NewFromUnsorted(input, func(a, b int) int { return a - b })
+
+			want := slices.Clone(tc.values)
+			slices.Sort(want)
+			if got := tree.SortedSnapshot(); !slices.Equal(got, want) {
+				t.Errorf("elements = %v, want %v", got, want)
+			}
+			if !slices.Equal(input, tc.values) {
+				t.Error("NewFromUnsorted() modified its input")
+			}
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+			if err := tree.CheckOrderStatistics(); err != nil {
+				t.Errorf("CheckOrderStatistics() = %v", err)
+			}
+		})
+	}
+
+	t.Run("applies_options", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewFromUnsorted([]int{4, 1, 3, 2}, func(a, b int) int { return a - b },
+			WithMaxSize[int](2, EvictLargest))
+		if got, want := tree.SortedSnapshot(), []int{1, 2}; !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestNewSetFromSliceCounting(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		vals           []int
+		want           []int
+		wantCollisions int
+	}{
+		{name: "empty", vals: nil, want: nil, wantCollisions: 0},
+		{name: "no_duplicates", vals: []int{3, 1, 2}, want: []int{1, 2, 3}, wantCollisions: 0},
+		{name: "some_duplicates", vals: []int{5, 1, 5, 3, 1, 5}, want: []int{1, 3, 5}, wantCollisions: 3},
+		{name: "all_equal", vals: []int{7, 7, 7, 7}, want: []int{7}, wantCollisions: 3},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			original := slices.Clone(tc.vals)
+			tree, collisions := NewSetFromSliceCounting(tc.vals, func(a, b int) int { return a - b })
+			if got := tree.SortedSnapshot(); !slices.Equal(got, tc.want) {
+				t.Errorf("contents = %v, want %v", got, tc.want)
+			}
+			if collisions != tc.wantCollisions {
+				t.Errorf("collisions = %d, want %d", collisions, tc.wantCollisions)
+			}
+			if tree.Size()+collisions != len(tc.vals) {
+				t.Errorf("Size() + collisions = %d, want %d", tree.Size()+collisions, len(tc.vals))
+			}
+			if !slices.Equal(tc.vals, original) {
+				t.Errorf("input modified: %v, want %v", tc.vals, original)
+			}
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		})
+	}
+
+	t.Run("keeps_first_of_equal", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct {
+			id  int
+			tag string
+		}
+		tree, collisions := NewSetFromSliceCounting(
+			[]item{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}},
+			func(a, b item) int { return a.id - b.id },
+		)
+		if got, want := tree.SortedSnapshot(), []item{{1, "b"}, {2, "a"}}; !slices.Equal(got, want) || collisions != 2 {
+			t.Errorf("NewSetFromSliceCounting() = %v, %d, want %v, 2", got, collisions, want)
+		}
+	})
+}
+
+func TestSubtreeByRank(t *testing.T) {
+	t.Parallel()
+
+	values := make([]int, 0, 90)
+	for i := 0; i < 90; i++ {
+		values = append(values, (i*7)%90)
+	}
+	tree := buildTree(values)
+
+	testCases := []struct {
+		name     string
+		i, j     int
+		wantSize int
+	}{
+		{name: "middle_third", i: 30, j: 60, wantSize: 30},
+		{name: "whole_tree", i: 0, j: 90, wantSize: 90},
+		{name: "clamped", i: -5, j: 200, wantSize: 90},
+		{name: "empty_range", i: 40, j: 40, wantSize: 0},
+		{name: "inverted_range", i: 50, j: 10, wantSize: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sub := tree.SubtreeByRank(tc.i, tc.j)
+			if sub.Size() != tc.wantSize {
+				t.Fatalf("Size() = %d, want %d", sub.Size(), tc.wantSize)
+			}
+			if tc.wantSize > 0 {
+				want := tree.SortedSnapshot()[max(tc.i, 0):min(tc.j, tree.Size())]
+				if got := sub.SortedSnapshot(); !slices.Equal(got, want) {
+					t.Errorf("SubtreeByRank(%d, %d) = %v, want %v", tc.i, tc.j, got, want)
+				}
+			}
+			checkRedBlackProperties(t, sub)
+			verifySizes(t, sub.root, sub.nil)
+		})
+	}
+
+	t.Run("original_intact", func(t *testing.T) {
+		t.Parallel()
+
+		sub := tree.SubtreeByRank(30, 60)
+		sub.Insert(1000)
+		if tree.Size() != 90 || tree.Search(1000) {
+			t.Error("modifying the extracted tree changed the original")
+		}
+		if got, want := sub.SortedSnapshot()[:3], []int{30, 31, 32}; !slices.Equal(got, want) {
+			t.Errorf("middle third starts with %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package gostree
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// By returns a comparison function that orders elements by the key extracted with keyFn,
+// compared using compare.
+func By[T, K any](keyFn func(T) K, compare CompareFunc[K]) CompareFunc[T] {
+	return func(a, b T) int {
+		return compare(keyFn(a), keyFn(b))
+	}
+}
+
+// Then returns a comparison function that orders elements by a,
+// breaking ties with b.
+func Then[T any](a, b CompareFunc[T]) CompareFunc[T] {
+	return func(x, y T) int {
+		if c := a(x, y); c != 0 {
+			return c
+		}
+
+		return b(x, y)
+	}
+}
+
+// Then returns a comparison function that orders elements by c,
+// breaking ties with next. It allows chaining, e.g. By(f, cmp.Compare).Then(By(g, cmp.Compare)).
+func (c CompareFunc[T]) Then(next CompareFunc[T]) CompareFunc[T] {
+	return Then(c, next)
+}
+
+// Comparator returns the comparison function used by the tree, so generic code can
+// create sibling trees with the same ordering. Callers must not assume anything about
+// the identity of the returned function: options such as WithComparatorCounter wrap
+// the function passed to NewTree, and calls through the wrapper are counted.
+func (t *Tree[T]) Comparator() CompareFunc[T] {
+	return t.compare
+}
+
+// CheckComparatorConsistency verifies that compare orders every pair of samples
+// the same way as cmp.Compare, the natural ordering of T, and returns an error
+// describing the first disagreement. It is a diagnostic for custom comparators on
+// ordered types that are expected to follow the natural ordering, and runs in
+// O(len(samples)^2) comparisons.
+func CheckComparatorConsistency[T cmp.Ordered](compare CompareFunc[T], samples []T) error {
+	for _, a := range samples {
+		for _, b := range samples {
+			if got, want := sign(compare(a, b)), cmp.Compare(a, b); got != want {
+				return fmt.Errorf("gostree: compare(%v, %v) has sign %d, natural ordering has %d", a, b, got, want)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package gostree
+
+import (
+	"cmp"
+	"strings"
+	"testing"
+)
+
+type person struct {
+	name string
+	age  int
+}
+
+func TestBy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("orders_by_extracted_key", func(t *testing.T) {
+		t.Parallel()
+
+		compare := By(func(p person) int { return p.age }, cmp.Compare[int])
+
+		if compare(person{name: "a", age: 30}, person{name: "b", age: 20}) <= 0 {
+			t.Error("expected age 30 to compare greater than age 20")
+		}
+		if compare(person{name: "a", age: 20}, person{name: "b", age: 20}) != 0 {
+			t.Error("expected equal ages to compare equal")
+		}
+	})
+
+	t.Run("works_with_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree(By(func(s string) int { return len(s) }, cmp.Compare[int]))
+		for _, s := range []string{"ccc", "a", "bb", "dddd"} {
+			tree.Insert(s)
+		}
+
+		expected := []string{"a", "bb", "ccc", "dddd"}
+		for i, want := range expected {
+			got, ok := tree.Select(i)
+			if !ok || got != want {
+				t.Errorf("Select(%d) = %q, %v; want %q, true", i, got, ok, want)
+			}
+		}
+	})
+}
+
+func TestThen(t *testing.T) {
+	t.Parallel()
+
+	byAge := By(func(p person) int { return p.age }, cmp.Compare[int])
+	byName := By(func(p person) string { return p.name }, strings.Compare)
+
+	people := []person{
+		{name: "carol", age: 30},
+		{name: "alice", age: 25},
+		{name: "bob", age: 30},
+		{name: "dave", age: 25},
+		{name: "alice", age: 30},
+	}
+	expected := []person{
+		{name: "alice", age: 25},
+		{name: "dave", age: 25},
+		{name: "alice", age: 30},
+		{name: "bob", age: 30},
+		{name: "carol", age: 30},
+	}
+
+	comparators := []struct {
+		name    string
+		compare CompareFunc[person]
+	}{
+		{"function", Then(byAge, byName)},
+		{"method", byAge.Then(byName)},
+	}
+
+	for _, c := range comparators {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := NewTree(c.compare)
+			for _, p := range people {
+				tree.Insert(p)
+			}
+
+			for i, want := range expected {
+				got, ok := tree.Select(i)
+				if !ok || got != want {
+					t.Errorf("Select(%d) = %+v, %v; want %+v, true", i, got, ok, want)
+				}
+			}
+		})
+	}
+
+	t.Run("first_comparator_takes_precedence", func(t *testing.T) {
+		t.Parallel()
+
+		compare := Then(byAge, byName)
+		if compare(person{name: "zed", age: 1}, person{name: "amy", age: 2}) >= 0 {
+			t.Error("expected younger person to compare less regardless of name")
+		}
+	})
+}
+
+func TestComparator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		if NewTree[int](nil).Comparator() != nil {
+			t.Error("Comparator() != nil for a tree without comparator")
+		}
+	})
+
+	t.Run("same_ordering", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return b - a }) // descending
+		compare := tree.Comparator()
+		for _, pair := range [][2]int{{1, 2}, {2, 1}, {3, 3}} {
+			if got, want := compare(pair[0], pair[1]), pair[1]-pair[0]; got != want {
+				t.Errorf("Comparator()(%d, %d) = %d, want %d", pair[0], pair[1], got, want)
+			}
+		}
+
+		sibling := NewTree(compare)
+		for _, v := range []int{3, 1, 2} {
+			tree.Insert(v)
+			sibling.Insert(v)
+		}
+		if IntersectionCount(tree, sibling) != 3 {
+			t.Error("sibling tree built with Comparator() should have the same ordering")
+		}
+	})
+}
+
+func TestCheckComparatorConsistency(t *testing.T) {
+	t.Parallel()
+
+	samples := []int{3, -1, 0, 42, 3, 7}
+
+	testCases := []struct {
+		name    string
+		compare CompareFunc[int]
+		wantErr bool
+	}{
+		{name: "natural", compare: cmp.Compare[int], wantErr: false},
+		{name: "subtraction", compare: func(a, b int) int { return a - b }, wantErr: false},
+		{name: "inverted", compare: func(a, b int) int { return b - a }, wantErr: true},
+		{name: "always_equal", compare: func(int, int) int { return 0 }, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := CheckComparatorConsistency(tc.compare, samples)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckComparatorConsistency() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	t.Run("absolute_value_with_negatives", func(t *testing.T) {
+		t.Parallel()
+
+		byAbs := func(a, b int) int { return abs(a) - abs(b) }
+		if err := CheckComparatorConsistency(byAbs, []int{-5, 2}); err == nil {
+			t.Error("CheckComparatorConsistency() = nil, want error for -5 vs 2")
+		}
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		t.Parallel()
+
+		if err := CheckComparatorConsistency(cmp.Compare[string], []string{"b", "a", "c"}); err != nil {
+			t.Errorf("CheckComparatorConsistency() = %v, want nil", err)
+		}
+	})
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
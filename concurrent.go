@@ -0,0 +1,154 @@
+package gostree
+
+import "sync"
+
+// ConcurrentMap is an ordered map that is safe for concurrent use.
+// It guards a Map with a read-write mutex: lookups share the read lock
+// and modifications take the write lock.
+type ConcurrentMap[K, V any] struct {
+	mu sync.RWMutex
+	m  *Map[K, V]
+}
+
+// NewConcurrentMap creates a new concurrent ordered map with keys ordered by compare.
+func NewConcurrentMap[K, V any](compare CompareFunc[K], opts ...MapOption[K, V]) *ConcurrentMap[K, V] {
+	return &ConcurrentMap[K, V]{
+		mu: sync.RWMutex{},
+		m:  NewMap(compare, opts...),
+	}
+}
+
+// Put associates value with key and reports whether the key was newly added.
+// See Map.Put for how an existing key is handled.
+func (c *ConcurrentMap[K, V]) Put(key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.m.Put(key, value)
+}
+
+// Get returns the value associated with key, or false if the key is absent.
+func (c *ConcurrentMap[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.m.Get(key)
+}
+
+// Delete removes key and its value from the map and reports whether it was present.
+func (c *ConcurrentMap[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.m.Delete(key)
+}
+
+// CompareAndDelete removes key only if its current value is equal to expected
+// according to eq, and reports whether it did. The check and the removal happen
+// atomically under the write lock, so a caller that read the value earlier can
+// delete it without racing a concurrent update.
+func (c *ConcurrentMap[K, V]) CompareAndDelete(key K, expected V, eq func(a, b V) bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.m.find(key)
+	if node == c.m.tree.nil || !eq(node.key.value, expected) {
+		return false
+	}
+	c.m.tree.deleteNode(node)
+
+	return true
+}
+
+// Len returns the number of entries in the map.
+func (c *ConcurrentMap[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.m.Len()
+}
+
+// ConcurrentTree is an order-statistic tree that is safe for concurrent use.
+// It guards a Tree with a read-write mutex: queries share the read lock
+// and modifications take the write lock.
+type ConcurrentTree[T any] struct {
+	mu   sync.RWMutex
+	tree *Tree[T]
+}
+
+// NewConcurrentTree creates a new concurrent tree ordered by compare.
+func NewConcurrentTree[T any](compare CompareFunc[T], opts ...Option[T]) *ConcurrentTree[T] {
+	return &ConcurrentTree[T]{
+		mu:   sync.RWMutex{},
+		tree: NewTree(compare, opts...),
+	}
+}
+
+// Insert adds key to the tree.
+func (c *ConcurrentTree[T]) Insert(key T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tree.Insert(key)
+}
+
+// Delete removes one occurrence of key and reports whether it was present.
+func (c *ConcurrentTree[T]) Delete(key T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tree.Delete(key)
+}
+
+// Search reports whether key is present.
+func (c *ConcurrentTree[T]) Search(key T) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tree.Search(key)
+}
+
+// Select returns the k-th smallest element (0-indexed), or false if k is out of range.
+func (c *ConcurrentTree[T]) Select(k int) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tree.Select(k)
+}
+
+// Rank returns the number of elements less than key.
+func (c *ConcurrentTree[T]) Rank(key T) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tree.Rank(key)
+}
+
+// Size returns the number of elements in the tree.
+func (c *ConcurrentTree[T]) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tree.Size()
+}
+
+// SnapshotIter returns an iterator over the elements in ascending order as they
+// were when SnapshotIter was called. The elements are copied under the read lock,
+// which is released before returning, so iteration holds no lock: writers are never
+// blocked by a slow reader, and changes made after the call are not seen. Every
+// iteration yields the same snapshot. The copy costs O(n) time and memory.
+//
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+func (c *ConcurrentTree[T]) SnapshotIter() func(yield func(T) bool) {
+	c.mu.RLock()
+	snapshot := c.tree.SortedSnapshot()
+	c.mu.RUnlock()
+
+	return func(yield func(T) bool) {
+		for _, key := range snapshot {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package gostree
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentMapCompareAndDelete(t *testing.T) {
+	t.Parallel()
+
+	eq := func(a, b string) bool { return a == b }
+
+	testCases := []struct {
+		name        string
+		key         int
+		expected    string
+		want        bool
+		wantPresent bool
+		wantLen     int
+	}{
+		{name: "match", key: 2, expected: "two", want: true, wantPresent: false, wantLen: 2},
+		{name: "value_mismatch", key: 2, expected: "TWO", want: false, wantPresent: true, wantLen: 3},
+		{name: "absent_key", key: 4, expected: "four", want: false, wantPresent: false, wantLen: 3},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewConcurrentMap[int, string](func(a, b int) int { return a - b })
+			m.Put(1, "one")
+			m.Put(2, "two")
+			m.Put(3, "three")
+
+			if got := m.CompareAndDelete(tc.key, tc.expected, eq); got != tc.want {
+				t.Errorf("CompareAndDelete(%d, %q) = %v, want %v", tc.key, tc.expected, got, tc.want)
+			}
+			if m.Len() != tc.wantLen {
+				t.Errorf("Len() = %d, want %d", m.Len(), tc.wantLen)
+			}
+			if _, ok := m.Get(tc.key); ok != tc.wantPresent {
+				t.Errorf("Get(%d) ok = %v, want %v", tc.key, ok, tc.wantPresent)
+			}
+		})
+	}
+
+	t.Run("single_winner", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewConcurrentMap[int, string](func(a, b int) int { return a - b })
+		m.Put(1, "token")
+
+		var wg sync.WaitGroup
+		results := make([]bool, 8)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = m.CompareAndDelete(1, "token", eq)
+			}(i)
+		}
+		wg.Wait()
+
+		wins := 0
+		for _, ok := range results {
+			if ok {
+				wins++
+			}
+		}
+		if wins != 1 || m.Len() != 0 {
+			t.Errorf("%d deletions succeeded, Len() = %d, want 1, 0", wins, m.Len())
+		}
+	})
+}
+
+func TestConcurrentTreeSnapshotIter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("point_in_time", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewConcurrentTree[int](func(a, b int) int { return a - b })
+		for _, v := range []int{3, 1, 2} {
+			tree.Insert(v)
+		}
+
+		seq := tree.SnapshotIter()
+		tree.Insert(0)
+		tree.Delete(2)
+
+		for i := 0; i < 2; i++ {
+			var got []int
+			seq(func(v int) bool {
+				got = append(got, v)
+
+				return true
+			})
+			if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+				t.Errorf("iteration %d = %v, want %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("early_stop", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewConcurrentTree[int](func(a, b int) int { return a - b })
+		for v := 0; v < 10; v++ {
+			tree.Insert(v)
+		}
+
+		var got []int
+		tree.SnapshotIter()(func(v int) bool {
+			got = append(got, v)
+
+			return v < 2
+		})
+		if want := []int{0, 1, 2}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	// Run with -race: readers iterate snapshots while a writer keeps inserting
+	t.Run("concurrent_writer", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewConcurrentTree[int](func(a, b int) int { return a - b })
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for v := 0; v < 2000; v++ {
+				tree.Insert(v)
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					var got []int
+					tree.SnapshotIter()(func(v int) bool {
+						got = append(got, v)
+
+						return true
+					})
+					for j, v := range got {
+						if v != j {
+							t.Errorf("snapshot is not a prefix of the inserted keys at %d: %d", j, v)
+
+							return
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		<-done
+
+		if tree.Size() != 2000 {
+			t.Errorf("Size() = %d, want 2000", tree.Size())
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package gostree
+
+import "errors"
+
+var (
+	// ErrOutOfRange is returned when a rank is outside [0, Size()).
+	ErrOutOfRange = errors.New("gostree: rank out of range")
+	// ErrNotFound is returned when a key is not present in the tree.
+	ErrNotFound = errors.New("gostree: key not found")
+	// ErrNilComparator is returned when the tree has no comparison function.
+	ErrNilComparator = errors.New("gostree: nil comparator")
+	// ErrNoCodec is returned by MarshalBinary and UnmarshalBinary when WithCodec is not set.
+	ErrNoCodec = errors.New("gostree: no key codec")
+	// ErrCorruptData is returned by UnmarshalBinary when the input is malformed.
+	ErrCorruptData = errors.New("gostree: corrupt binary data")
+	// ErrNotAscending is returned by InsertAscending when the key is less than the maximum.
+	ErrNotAscending = errors.New("gostree: key is less than the maximum")
+)
+
+// TrySelect is like Select but returns ErrOutOfRange instead of false
+// when k is outside [0, Size()).
+func (t *Tree[T]) TrySelect(k int) (T, error) {
+	key, ok := t.Select(k)
+	if !ok {
+		return key, ErrOutOfRange
+	}
+
+	return key, nil
+}
+
+// TryInsert is like Insert but returns ErrNilComparator instead of panicking
+// when the tree was created without a comparison function.
+func (t *Tree[T]) TryInsert(key T) error {
+	if t.compare == nil {
+		return ErrNilComparator
+	}
+	t.Insert(key)
+
+	return nil
+}
+
+// InsertAscending appends a key that is greater than or equal to the current maximum,
+// returning ErrNotAscending and leaving the tree unchanged otherwise.
+// It makes a single comparison against the maximum instead of searching for the
+// insertion position, which speeds up loading data that is already sorted
+// and catches ordering mistakes in it. The maximum is cached with WithSortedAppend
+// and otherwise found with one walk down the right spine, which compares nothing.
+// Bounded trees evict as in Insert, and a tree in reservoir mode samples the key
+// as Insert does.
+func (t *Tree[T]) InsertAscending(key T) error {
+	if t.compare == nil {
+		return ErrNilComparator
+	}
+
+	maximum := t.maxNode
+	if maximum == nil && t.root != t.nil {
+		maximum = t.maximum(t.root)
+	}
+	if t.root != t.nil && t.compare(key, maximum.key) < 0 {
+		return ErrNotAscending
+	}
+	if t.reservoir != nil {
+		t.sample(key)
+
+		return nil
+	}
+	if t.maxSize > 0 && t.root.size >= t.maxSize {
+		t.evict()
+		if t.evictPolicy == EvictLargest && t.root != t.nil {
+			// The previous maximum was the element evicted
+			maximum = t.maximum(t.root)
+		}
+	}
+	t.checkCapacity()
+
+	if t.root == t.nil {
+		t.insert(key)
+	} else {
+		t.appendAfter(maximum, key)
+	}
+
+	return nil
+}
+
+// TryDelete is like Delete but returns ErrNotFound instead of false
+// when the key is not present, and ErrNilComparator instead of panicking
+// when the tree was created without a comparison function.
+func (t *Tree[T]) TryDelete(key T) error {
+	if t.compare == nil {
+		return ErrNilComparator
+	}
+	if !t.Delete(key) {
+		return ErrNotFound
+	}
+
+	return nil
+}
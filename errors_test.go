@@ -0,0 +1,209 @@
+package gostree
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTrySelect(t *testing.T) {
+	t.Parallel()
+
+	t.Run("in_range", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{30, 10, 20})
+
+		val, err := tree.TrySelect(1)
+		if err != nil || val != 20 {
+			t.Errorf("TrySelect(1) = %d, %v; want 20, nil", val, err)
+		}
+	})
+
+	t.Run("out_of_range", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{30, 10, 20})
+
+		for _, k := range []int{-1, 3, 100} {
+			if _, err := tree.TrySelect(k); !errors.Is(err, ErrOutOfRange) {
+				t.Errorf("TrySelect(%d) error = %v, want ErrOutOfRange", k, err)
+			}
+		}
+	})
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, err := tree.TrySelect(0); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("TrySelect(0) error = %v, want ErrOutOfRange", err)
+		}
+	})
+}
+
+func TestTryInsert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inserts_key", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if err := tree.TryInsert(42); err != nil {
+			t.Fatalf("TryInsert(42) error = %v, want nil", err)
+		}
+		if !tree.Search(42) {
+			t.Error("Search should find inserted key")
+		}
+	})
+
+	t.Run("nil_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](nil)
+		tree.Insert(1) // inserting into an empty tree needs no comparisons
+
+		if err := tree.TryInsert(2); !errors.Is(err, ErrNilComparator) {
+			t.Errorf("TryInsert(2) error = %v, want ErrNilComparator", err)
+		}
+		if size := tree.Size(); size != 1 {
+			t.Errorf("Size() = %d, want 1", size)
+		}
+	})
+}
+
+func TestTryDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deletes_key", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		if err := tree.TryDelete(2); err != nil {
+			t.Fatalf("TryDelete(2) error = %v, want nil", err)
+		}
+		if tree.Search(2) {
+			t.Error("deleted key should not be found")
+		}
+	})
+
+	t.Run("missing_key", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		if err := tree.TryDelete(4); !errors.Is(err, ErrNotFound) {
+			t.Errorf("TryDelete(4) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("nil_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](nil)
+		if err := tree.TryDelete(1); !errors.Is(err, ErrNilComparator) {
+			t.Errorf("TryDelete(1) error = %v, want ErrNilComparator", err)
+		}
+	})
+}
+
+func TestInsertAscending(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sorted_input", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		var want []int
+		for i := 0; i < 200; i++ {
+			v := i / 3 // duplicates of the maximum are accepted
+			if err := tree.InsertAscending(v); err != nil {
+				t.Fatalf("InsertAscending(%d) error = %v", v, err)
+			}
+			want = append(want, v)
+		}
+
+		if got := tree.SortedSnapshot(); !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("out_of_order_input", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30})
+		if err := tree.InsertAscending(25); !errors.Is(err, ErrNotAscending) {
+			t.Errorf("InsertAscending(25) error = %v, want ErrNotAscending", err)
+		}
+		if got, want := tree.SortedSnapshot(), []int{10, 20, 30}; !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+		if err := tree.InsertAscending(31); err != nil {
+			t.Errorf("InsertAscending(31) error = %v, want nil", err)
+		}
+	})
+
+	t.Run("compares_once", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithComparatorCounter[int]())
+		for i := 0; i < 100; i++ {
+			_ = tree.InsertAscending(i)
+		}
+		if got := tree.ComparatorCalls(); got != 99 {
+			t.Errorf("ComparatorCalls() = %d, want 99", got)
+		}
+	})
+
+	t.Run("bounded_tree_evicts", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](3, EvictSmallest))
+		for i := 1; i <= 5; i++ {
+			_ = tree.InsertAscending(i)
+		}
+		if got, want := tree.SortedSnapshot(), []int{3, 4, 5}; !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("bounded_tree_evicts_largest", func(t *testing.T) {
+		t.Parallel()
+
+		for _, opts := range [][]Option[int]{
+			{WithMaxSize[int](3, EvictLargest)},
+			{WithMaxSize[int](3, EvictLargest), WithSortedAppend[int]()},
+		} {
+			tree := NewTree[int](func(a, b int) int { return a - b }, opts...)
+			for i := 1; i <= 5; i++ {
+				if err := tree.InsertAscending(i); err != nil {
+					t.Fatalf("InsertAscending(%d) error = %v", i, err)
+				}
+			}
+			// Each insert past the bound evicts the previous maximum
+			if got, want := tree.SortedSnapshot(), []int{1, 2, 5}; !slices.Equal(got, want) {
+				t.Errorf("elements = %v, want %v", got, want)
+			}
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		}
+
+		single := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](1, EvictSmallest))
+		for i := 1; i <= 3; i++ {
+			_ = single.InsertAscending(i)
+		}
+		if got, want := single.SortedSnapshot(), []int{3}; !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("nil_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](nil)
+		if err := tree.InsertAscending(1); !errors.Is(err, ErrNilComparator) {
+			t.Errorf("InsertAscending() error = %v, want ErrNilComparator", err)
+		}
+	})
+}
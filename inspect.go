@@ -0,0 +1,313 @@
+package gostree
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WalkNodes performs a pre-order walk of the tree and calls fn for every node
+// with its key, color ("R" or "B"), subtree size and depth (0 for the root).
+// The walk stops early when fn returns false.
+//
+// It exposes structural metadata read-only and is intended for external
+// renderers, validators and diagnostics.
+func (t *Tree[T]) WalkNodes(fn func(key T, color string, size int, depth int) bool) {
+	type frame struct {
+		node  *Node[T]
+		depth int
+	}
+
+	if t.root == t.nil {
+		return
+	}
+
+	stack := []frame{{node: t.root, depth: 0}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		color := "R"
+		if top.node.color == BLACK {
+			color = "B"
+		}
+		if !fn(top.node.key, color, top.node.size, top.depth) {
+			return
+		}
+
+		// Push right first so the left subtree is visited first
+		if top.node.right != t.nil {
+			stack = append(stack, frame{node: top.node.right, depth: top.depth + 1})
+		}
+		if top.node.left != t.nil {
+			stack = append(stack, frame{node: top.node.left, depth: top.depth + 1})
+		}
+	}
+}
+
+// TreeStats holds aggregate metrics describing a tree.
+type TreeStats[T any] struct {
+	Size         int // number of elements
+	Height       int // number of nodes on the longest root-to-leaf path, 0 for an empty tree
+	BlackHeight  int // number of BLACK nodes on any root-to-leaf path, 0 for an empty tree
+	RedNodes     int // number of RED nodes
+	DistinctKeys int // number of distinct keys according to the comparator
+	Min          T   // smallest key, zero value for an empty tree
+	Max          T   // largest key, zero value for an empty tree
+}
+
+// Stats returns aggregate metrics of the tree computed in two traversals.
+func (t *Tree[T]) Stats() TreeStats[T] {
+	var stats TreeStats[T]
+	stats.Size = t.root.size
+	if t.root == t.nil {
+		return stats
+	}
+
+	t.WalkNodes(func(_ T, color string, _ int, depth int) bool {
+		if depth+1 > stats.Height {
+			stats.Height = depth + 1
+		}
+		if color == "R" {
+			stats.RedNodes++
+		}
+
+		return true
+	})
+
+	for node := t.root; node != t.nil; node = node.left {
+		if node.color == BLACK {
+			stats.BlackHeight++
+		}
+	}
+
+	first := t.first()
+	stats.Min = first.key
+	stats.Max = t.maximum(t.root).key
+	stats.DistinctKeys = 1
+	for prev, node := first, t.successor(first); node != t.nil; prev, node = node, t.successor(node) {
+		if t.compare(prev.key, node.key) != 0 {
+			stats.DistinctKeys++
+		}
+	}
+
+	return stats
+}
+
+// CheckOrderStatistics verifies that Select and Rank agree with the in-order
+// traversal of the tree and returns an error describing the first inconsistency.
+// For every rank i it checks that Select(i) returns the i-th node of the traversal
+// and that Rank(Select(i)) <= i < Rank(Select(i)) + count of equal keys.
+//
+// It runs in O(n log n), does not modify the tree and is intended for invariant
+// checks in tests after custom operations.
+func (t *Tree[T]) CheckOrderStatistics() error {
+	i := 0
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		if i >= t.root.size {
+			return fmt.Errorf("in-order traversal has more than Size() = %d elements", t.root.size)
+		}
+		if selected := t.selectNode(t.root, i); selected != node {
+			return fmt.Errorf("Select(%d) = %v, in-order element %d is %v", i, selected.key, i, node.key)
+		}
+		if rank := t.Rank(node.key); rank > i {
+			return fmt.Errorf("Rank(Select(%d)) = %d, want <= %d", i, rank, i)
+		}
+		if upper := t.rankUpper(node.key); upper <= i {
+			return fmt.Errorf("%d elements <= Select(%d), want > %d", upper, i, i)
+		}
+		i++
+	}
+	if i != t.root.size {
+		return fmt.Errorf("in-order traversal has %d elements, Size() = %d", i, t.root.size)
+	}
+
+	return nil
+}
+
+// Depth returns the number of edges from the root to the node holding key
+// (0 for the root), or false if the key is absent. With duplicates it reports
+// the first matching node found by Search, which is the shallowest one.
+func (t *Tree[T]) Depth(key T) (int, bool) {
+	depth := 0
+	for current := t.root; current != t.nil; depth++ {
+		cmp := t.compare(key, current.key)
+		if cmp == 0 {
+			return depth, true
+		} else if cmp < 0 {
+			current = current.left
+		} else {
+			current = current.right
+		}
+	}
+
+	return 0, false
+}
+
+// RecomputeSizes rebuilds every node's subtree size from its children in O(n),
+// repairing any drift left by manual structural changes. It is a maintenance tool;
+// the tree's own operations always keep sizes up to date.
+func (t *Tree[T]) RecomputeSizes() {
+	if t.root == t.nil {
+		return
+	}
+
+	// Parents precede their children in pre-order, so visiting the nodes
+	// in reverse computes every child before its parent
+	nodes := []*Node[T]{}
+	stack := []*Node[T]{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, node)
+		if node.right != t.nil {
+			stack = append(stack, node.right)
+		}
+		if node.left != t.nil {
+			stack = append(stack, node.left)
+		}
+	}
+
+	for i := len(nodes) - 1; i >= 0; i-- {
+		nodes[i].size = nodes[i].left.size + nodes[i].right.size + 1
+	}
+}
+
+// SameStructure reports whether a and b have the same shape: node for node, the
+// keys compare equal under a's comparator and the colors and subtree sizes match.
+// Trees holding the same elements in different shapes are not the same, so it
+// is stricter than comparing SortedSnapshot results. It stops at the first
+// difference and is intended for asserting that Clone or a bulk build is
+// structurally deterministic.
+func SameStructure[T any](a, b *Tree[T]) bool {
+	return a.sameSubtree(a.root, b, b.root)
+}
+
+// sameSubtree reports whether the subtree at x in t matches the subtree at y in other
+func (t *Tree[T]) sameSubtree(x *Node[T], other *Tree[T], y *Node[T]) bool {
+	if x == t.nil || y == other.nil {
+		return x == t.nil && y == other.nil
+	}
+	if x.color != y.color || x.size != y.size || t.compare(x.key, y.key) != 0 {
+		return false
+	}
+
+	return t.sameSubtree(x.left, other, y.left) && t.sameSubtree(x.right, other, y.right)
+}
+
+// Validate checks that the tree satisfies all red-black and order-statistic
+// invariants and returns an error describing the first violation found:
+// the root and sentinel are BLACK, no RED node has a RED child, every path has
+// the same number of BLACK nodes, parent links match child links, every size
+// equals the size of its subtree, and keys are in non-decreasing order.
+//
+// It runs in O(n), does not modify the tree and is intended for tests and
+// debugging after custom operations.
+func (t *Tree[T]) Validate() error {
+	if t.nil.color != BLACK || t.nil.size != 0 {
+		return errors.New("sentinel must be BLACK with size 0")
+	}
+
+	return t.validateFrom(t.root)
+}
+
+// validateFrom checks the invariants listed in Validate for the tree rooted at root,
+// which must use the sentinel of t but need not be attached to t yet. The root's
+// size is trusted to preallocate, so callers decoding untrusted data must bound it.
+// Key order is only checked if t has a comparator.
+func (t *Tree[T]) validateFrom(root *Node[T]) error {
+	if root == t.nil {
+		return nil
+	}
+	if root.color != BLACK {
+		return fmt.Errorf("root %v is RED", root.key)
+	}
+	if root.parent != t.nil {
+		return fmt.Errorf("root %v has a parent", root.key)
+	}
+
+	type frame struct {
+		node   *Node[T]
+		parent int // index of the parent frame, -1 for the root
+		isLeft bool
+	}
+
+	// Visit nodes in pre-order, checking local invariants
+	frames := make([]frame, 0, root.size)
+	stack := []frame{{node: root, parent: -1, isLeft: false}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		index := len(frames)
+		frames = append(frames, top)
+
+		node := top.node
+		if node.size != node.left.size+node.right.size+1 {
+			return fmt.Errorf("node %v has size %d, children sizes %d and %d",
+				node.key, node.size, node.left.size, node.right.size)
+		}
+		for _, child := range []*Node[T]{node.left, node.right} {
+			if child == t.nil {
+				continue
+			}
+			if child.parent != node {
+				return fmt.Errorf("child %v of node %v has a different parent", child.key, node.key)
+			}
+			if node.color == RED && child.color == RED {
+				return fmt.Errorf("RED node %v has RED child %v", node.key, child.key)
+			}
+		}
+
+		if node.right != t.nil {
+			stack = append(stack, frame{node: node.right, parent: index, isLeft: false})
+		}
+		if node.left != t.nil {
+			stack = append(stack, frame{node: node.left, parent: index, isLeft: true})
+		}
+	}
+	if len(frames) != root.size {
+		return fmt.Errorf("tree has %d nodes, root size is %d", len(frames), root.size)
+	}
+
+	// Children follow their parents in pre-order, so walking backwards computes
+	// black heights bottom-up; the sentinel counts as one BLACK node
+	leftHeight := make([]int, len(frames))
+	rightHeight := make([]int, len(frames))
+	for i := range frames {
+		leftHeight[i], rightHeight[i] = 1, 1
+	}
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		if leftHeight[i] != rightHeight[i] {
+			return fmt.Errorf("node %v has black heights %d and %d below its children",
+				f.node.key, leftHeight[i], rightHeight[i])
+		}
+		height := leftHeight[i]
+		if f.node.color == BLACK {
+			height++
+		}
+		switch {
+		case f.parent < 0:
+		case f.isLeft:
+			leftHeight[f.parent] = height
+		default:
+			rightHeight[f.parent] = height
+		}
+	}
+
+	if t.compare == nil {
+		return nil
+	}
+	first := t.minimum(root)
+	for prev, node := first, t.successor(first); node != t.nil; prev, node = node, t.successor(node) {
+		if t.compare(prev.key, node.key) > 0 {
+			return fmt.Errorf("key %v is ordered before smaller key %v", prev.key, node.key)
+		}
+	}
+
+	return nil
+}
+
+// IsValid reports whether the tree satisfies all invariants checked by Validate.
+func (t *Tree[T]) IsValid() bool {
+	return t.Validate() == nil
+}
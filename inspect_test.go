@@ -0,0 +1,411 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWalkNodes(t *testing.T) {
+	t.Parallel()
+
+	type visit struct {
+		key   int
+		color string
+		size  int
+		depth int
+	}
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		tree.WalkNodes(func(int, string, int, int) bool {
+			t.Error("fn should not be called for empty tree")
+
+			return true
+		})
+	})
+
+	t.Run("pre_order_with_metadata", func(t *testing.T) {
+		t.Parallel()
+
+		// 20(B) with children 10(B) and 30(B), 40(R) under 30
+		tree := buildTree([]int{10, 20, 30, 40})
+
+		var visits []visit
+		tree.WalkNodes(func(key int, color string, size int, depth int) bool {
+			visits = append(visits, visit{key: key, color: color, size: size, depth: depth})
+
+			return true
+		})
+
+		expected := []visit{
+			{key: 20, color: "B", size: 4, depth: 0},
+			{key: 10, color: "B", size: 1, depth: 1},
+			{key: 30, color: "B", size: 2, depth: 1},
+			{key: 40, color: "R", size: 1, depth: 2},
+		}
+		if len(visits) != len(expected) {
+			t.Fatalf("visited %d nodes, want %d", len(visits), len(expected))
+		}
+		for i, want := range expected {
+			if visits[i] != want {
+				t.Errorf("visit %d = %+v, want %+v", i, visits[i], want)
+			}
+		}
+	})
+
+	t.Run("stops_early", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5, 6, 7})
+
+		count := 0
+		tree.WalkNodes(func(int, string, int, int) bool {
+			count++
+
+			return count < 3
+		})
+
+		if count != 3 {
+			t.Errorf("fn called %d times, want 3", count)
+		}
+	})
+
+	t.Run("visits_every_node", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{50, 25, 75, 12, 37, 62, 87, 6, 18, 31, 43})
+
+		count := 0
+		tree.WalkNodes(func(_ int, color string, size int, _ int) bool {
+			if color != "R" && color != "B" {
+				t.Errorf("unexpected color %q", color)
+			}
+			if size < 1 {
+				t.Errorf("unexpected size %d", size)
+			}
+			count++
+
+			return true
+		})
+
+		if count != tree.Size() {
+			t.Errorf("visited %d nodes, want %d", count, tree.Size())
+		}
+	})
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+
+		var expected TreeStats[int]
+		if stats := tree.Stats(); stats != expected {
+			t.Errorf("Stats() = %+v, want %+v", stats, expected)
+		}
+	})
+
+	t.Run("known_tree", func(t *testing.T) {
+		t.Parallel()
+
+		// 20(B) with children 10(B) and 30(B), 40(R) under 30
+		tree := buildTree([]int{10, 20, 30, 40})
+
+		expected := TreeStats[int]{
+			Size:         4,
+			Height:       3,
+			BlackHeight:  2,
+			RedNodes:     1,
+			DistinctKeys: 4,
+			Min:          10,
+			Max:          40,
+		}
+		if stats := tree.Stats(); stats != expected {
+			t.Errorf("Stats() = %+v, want %+v", stats, expected)
+		}
+	})
+
+	t.Run("with_duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{5, 3, 7, 3, 5, 7, 5, 1})
+
+		stats := tree.Stats()
+		if stats.Size != 8 {
+			t.Errorf("Size = %d, want 8", stats.Size)
+		}
+		if stats.DistinctKeys != 4 {
+			t.Errorf("DistinctKeys = %d, want 4", stats.DistinctKeys)
+		}
+		if stats.Min != 1 || stats.Max != 7 {
+			t.Errorf("Min, Max = %d, %d; want 1, 7", stats.Min, stats.Max)
+		}
+	})
+
+	t.Run("height_is_logarithmic", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		for i := 0; i < 1023; i++ {
+			tree.Insert(i)
+		}
+
+		stats := tree.Stats()
+		if stats.Height < 10 || stats.Height > 20 {
+			t.Errorf("Height = %d, want between 10 and 20", stats.Height)
+		}
+		if stats.BlackHeight < 5 || stats.BlackHeight > stats.Height {
+			t.Errorf("BlackHeight = %d, want between 5 and %d", stats.BlackHeight, stats.Height)
+		}
+	})
+}
+
+func TestCheckOrderStatistics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid_trees", func(t *testing.T) {
+		t.Parallel()
+
+		for _, values := range [][]int{nil, {1}, {5, 3, 8, 3, 3, 9, 1}, {10, 20, 30, 40, 50, 60, 70}} {
+			tree := buildTree(values)
+			if err := tree.CheckOrderStatistics(); err != nil {
+				t.Errorf("CheckOrderStatistics() on %v = %v, want nil", values, err)
+			}
+		}
+	})
+
+	t.Run("after_deletes", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		tree.Delete(4)
+		tree.Delete(1)
+		tree.DeleteRankRange(2, 4)
+		if err := tree.CheckOrderStatistics(); err != nil {
+			t.Errorf("CheckOrderStatistics() = %v, want nil", err)
+		}
+	})
+
+	t.Run("corrupted_size", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30, 40, 50})
+		tree.root.left.size++
+		if err := tree.CheckOrderStatistics(); err == nil {
+			t.Error("CheckOrderStatistics() = nil, want error for corrupted size")
+		}
+	})
+
+	t.Run("read_only", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{3, 1, 2})
+		before := tree.SortedSnapshot()
+		_ = tree.CheckOrderStatistics()
+		if got := tree.SortedSnapshot(); !slices.Equal(got, before) {
+			t.Errorf("tree changed to %v, want %v", got, before)
+		}
+	})
+}
+
+func TestDepth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, ok := tree.Depth(1); ok {
+			t.Error("Depth() ok = true on empty tree, want false")
+		}
+	})
+
+	t.Run("known_shape", func(t *testing.T) {
+		t.Parallel()
+
+		// 20(B) with children 10(B) and 30(B), 40(R) under 30
+		tree := buildTree([]int{10, 20, 30, 40})
+
+		for key, want := range map[int]int{20: 0, 10: 1, 30: 1, 40: 2} {
+			if got, ok := tree.Depth(key); !ok || got != want {
+				t.Errorf("Depth(%d) = %d, %v, want %d, true", key, got, ok, want)
+			}
+		}
+		for _, key := range []int{5, 25, 50} {
+			if _, ok := tree.Depth(key); ok {
+				t.Errorf("Depth(%d) ok = true, want false", key)
+			}
+		}
+	})
+
+	t.Run("matches_walk", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{50, 20, 80, 10, 30, 70, 90, 25, 35, 65, 5})
+		tree.WalkNodes(func(key int, _ string, _ int, depth int) bool {
+			if got, ok := tree.Depth(key); !ok || got != depth {
+				t.Errorf("Depth(%d) = %d, %v, want %d, true", key, got, ok, depth)
+			}
+
+			return true
+		})
+	})
+}
+
+func TestRecomputeSizes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		tree.RecomputeSizes()
+		if tree.Size() != 0 || tree.nil.size != 0 {
+			t.Error("RecomputeSizes() should leave an empty tree empty")
+		}
+	})
+
+	t.Run("repairs_corrupted_sizes", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		for i := 0; i < 300; i++ {
+			tree.Insert((i * 7) % 101)
+		}
+
+		// Corrupt every size field
+		stack := []*Node[int]{tree.root}
+		for len(stack) > 0 {
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			node.size = 42
+			for _, child := range []*Node[int]{node.left, node.right} {
+				if child != tree.nil {
+					stack = append(stack, child)
+				}
+			}
+		}
+
+		tree.RecomputeSizes()
+
+		verifySizes(t, tree.root, tree.nil)
+		if tree.Size() != 300 {
+			t.Errorf("Size() = %d, want 300", tree.Size())
+		}
+		if err := tree.CheckOrderStatistics(); err != nil {
+			t.Errorf("CheckOrderStatistics() = %v", err)
+		}
+	})
+}
+
+func TestSameStructure(t *testing.T) {
+	t.Parallel()
+
+	ascending := func() *Tree[int] {
+		return buildTree([]int{1, 2, 3, 4, 5, 6, 7})
+	}
+
+	testCases := []struct {
+		name string
+		a, b *Tree[int]
+		want bool
+	}{
+		{name: "empty", a: buildTree(nil), b: buildTree(nil), want: true},
+		{name: "same_insert_order", a: ascending(), b: ascending(), want: true},
+		{name: "clone", a: ascending(), b: ascending().Clone(), want: true},
+		{name: "same_elements_different_shape", a: ascending(), b: buildTree([]int{4, 2, 6, 1, 3, 5, 7}), want: false},
+		{name: "different_elements", a: buildTree([]int{1, 2, 3}), b: buildTree([]int{1, 2, 4}), want: false},
+		{name: "one_empty", a: buildTree([]int{1}), b: buildTree(nil), want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := SameStructure(tc.a, tc.b); got != tc.want {
+				t.Errorf("SameStructure() = %v, want %v", got, tc.want)
+			}
+			if got := SameStructure(tc.b, tc.a); got != tc.want {
+				t.Errorf("SameStructure() reversed = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("color_difference", func(t *testing.T) {
+		t.Parallel()
+
+		// Both trees have the root 2 with children 1 and 3; after inserting and deleting
+		// 4 the children of the second tree are BLACK instead of RED
+		a := buildTree([]int{2, 1, 3})
+		b := buildTree([]int{2, 1, 3, 4})
+		b.Delete(4)
+		if !slices.Equal(a.SortedSnapshot(), b.SortedSnapshot()) {
+			t.Fatal("trees should hold the same elements")
+		}
+		if SameStructure(a, b) {
+			t.Error("SameStructure() = true for trees differing only in colors")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid_trees", func(t *testing.T) {
+		t.Parallel()
+
+		trees := map[string]*Tree[int]{
+			"empty":      NewTree[int](func(a, b int) int { return a - b }),
+			"inserted":   buildTree([]int{50, 20, 80, 10, 30, 70, 90, 25, 35, 65, 5, 30}),
+			"bulk_built": NewFromUnsorted([]int{9, 3, 3, 7, 1, 8, 2, 6, 5, 4, 0}, func(a, b int) int { return a - b }),
+		}
+		deleted := buildTree([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+		deleted.DeleteRankRange(2, 7)
+		trees["after_deletes"] = deleted
+
+		for name, tree := range trees {
+			if err := tree.Validate(); err != nil {
+				t.Errorf("%s: Validate() = %v, want nil", name, err)
+			}
+			if !tree.IsValid() {
+				t.Errorf("%s: IsValid() = false, want true", name)
+			}
+		}
+	})
+
+	// 20(B) with children 10(B) and 30(B), 40(R) under 30
+	corruptions := []struct {
+		name    string
+		corrupt func(tree *Tree[int])
+	}{
+		{name: "red_root", corrupt: func(tree *Tree[int]) { tree.root.color = RED }},
+		{name: "red_red", corrupt: func(tree *Tree[int]) { tree.root.right.color = RED }},
+		{name: "black_height", corrupt: func(tree *Tree[int]) { tree.root.right.right.color = BLACK }},
+		{name: "size", corrupt: func(tree *Tree[int]) { tree.root.left.size = 2 }},
+		{name: "parent_link", corrupt: func(tree *Tree[int]) { tree.root.right.right.parent = tree.root }},
+		{name: "order", corrupt: func(tree *Tree[int]) { tree.root.left.key = 25 }},
+	}
+
+	for _, tc := range corruptions {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree([]int{10, 20, 30, 40})
+			tc.corrupt(tree)
+
+			if err := tree.Validate(); err == nil {
+				t.Error("Validate() = nil, want error")
+			}
+			if tree.IsValid() {
+				t.Error("IsValid() = true, want false")
+			}
+		})
+	}
+}
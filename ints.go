@@ -0,0 +1,62 @@
+package gostree
+
+// LongestConsecutiveRun returns the start and length of the longest run of
+// consecutive integers present in the tree (e.g. 5, 6, 7, 8).
+// Duplicate keys do not extend a run. When several runs have the same length,
+// the one with the smallest start is returned. For an empty tree it returns 0, 0.
+func LongestConsecutiveRun(t *Tree[int]) (start int, length int) {
+	node := t.first()
+	if node == t.nil {
+		return 0, 0
+	}
+
+	start, length = node.key, 1
+	runStart, runLength, prev := node.key, 1, node.key
+	for node = t.successor(node); node != t.nil; node = t.successor(node) {
+		switch node.key {
+		case prev:
+			continue
+		case prev + 1:
+			runLength++
+		default:
+			runStart, runLength = node.key, 1
+		}
+		prev = node.key
+
+		if runLength > length {
+			start, length = runStart, runLength
+		}
+	}
+
+	return start, length
+}
+
+// OffsetKeys adds delta to every key in place. Adding a constant preserves the
+// order of keys, so the tree needs no restructuring and the shift takes O(n).
+// The caller must ensure no key overflows, which would break the ordering.
+func OffsetKeys(t *Tree[int], delta int) {
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		node.key += delta
+	}
+}
+
+// CoalesceRuns returns the maximal runs of consecutive integers present in the tree
+// as inclusive [start, end] ranges in ascending order, computed in a single in-order
+// walk. For example keys 1, 2, 3, 7, 8, 10 yield [1, 3], [7, 8], [10, 10].
+// Duplicate keys count as a single occupancy. For an empty tree it returns nil.
+func CoalesceRuns(t *Tree[int]) [][2]int {
+	var runs [][2]int
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		last := len(runs) - 1
+		switch {
+		case last >= 0 && node.key == runs[last][1]:
+			continue
+		case last >= 0 && node.key == runs[last][1]+1:
+			runs[last][1] = node.key
+		default:
+			runs = append(runs, [2]int{node.key, node.key})
+		}
+	}
+
+	return runs
+}
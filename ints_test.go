@@ -0,0 +1,129 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLongestConsecutiveRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		values         []int
+		expectedStart  int
+		expectedLength int
+	}{
+		{"empty_tree", nil, 0, 0},
+		{"single_element", []int{7}, 7, 1},
+		{"no_runs", []int{1, 3, 5, 7}, 1, 1},
+		{"single_run", []int{8, 5, 7, 6}, 5, 4},
+		{"with_gaps", []int{1, 2, 3, 10, 11, 12, 13, 20}, 10, 4},
+		{"with_duplicates", []int{4, 4, 5, 5, 5, 6, 9, 10}, 4, 3},
+		{"ties_prefer_smallest", []int{1, 2, 5, 6}, 1, 2},
+		{"negative_keys", []int{-3, -2, -1, 0, 5}, -3, 4},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree(tc.values)
+
+			start, length := LongestConsecutiveRun(tree)
+			if start != tc.expectedStart || length != tc.expectedLength {
+				t.Errorf("LongestConsecutiveRun() = %d, %d; want %d, %d",
+					start, length, tc.expectedStart, tc.expectedLength)
+			}
+		})
+	}
+}
+
+func TestOffsetKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree(nil)
+		OffsetKeys(tree, 10)
+
+		if size := tree.Size(); size != 0 {
+			t.Errorf("Size() = %d, want 0", size)
+		}
+	})
+
+	testCases := []struct {
+		name  string
+		delta int
+	}{
+		{"positive_delta", 1000},
+		{"negative_delta", -1000},
+		{"zero_delta", 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			values := []int{50, 20, 80, 10, 30, 70, 90, 20}
+			tree := buildTree(values)
+			before := tree.SortedSnapshot()
+			ranks := make([]int, len(values))
+			for i, v := range values {
+				ranks[i] = tree.Rank(v)
+			}
+
+			OffsetKeys(tree, tc.delta)
+
+			for i, orig := range before {
+				got, ok := tree.Select(i)
+				if !ok || got != orig+tc.delta {
+					t.Errorf("Select(%d) = %d, %v; want %d, true", i, got, ok, orig+tc.delta)
+				}
+			}
+			for i, v := range values {
+				if !tree.Search(v + tc.delta) {
+					t.Errorf("Search(%d) should find shifted key", v+tc.delta)
+				}
+				if rank := tree.Rank(v + tc.delta); rank != ranks[i] {
+					t.Errorf("Rank(%d) = %d, want %d", v+tc.delta, rank, ranks[i])
+				}
+			}
+
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+			verifyTreeIntegrity(t, tree)
+		})
+	}
+}
+
+func TestCoalesceRuns(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		values []int
+		want   [][2]int
+	}{
+		{name: "empty", values: nil, want: nil},
+		{name: "single", values: []int{4}, want: [][2]int{{4, 4}}},
+		{name: "gaps", values: []int{1, 2, 3, 7, 8, 10}, want: [][2]int{{1, 3}, {7, 8}, {10, 10}}},
+		{name: "duplicates", values: []int{5, 5, 6, 6, 6, 7, 9, 9}, want: [][2]int{{5, 7}, {9, 9}}},
+		{name: "negative_keys", values: []int{-3, -2, -1, 0, 2}, want: [][2]int{{-3, 0}, {2, 2}}},
+		{name: "all_isolated", values: []int{0, 2, 4}, want: [][2]int{{0, 0}, {2, 2}, {4, 4}}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := CoalesceRuns(buildTree(tc.values)); !slices.Equal(got, tc.want) {
+				t.Errorf("CoalesceRuns(%v) = %v, want %v", tc.values, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gostree
+
+// CDF returns an iterator over the distinct keys in ascending order, each paired
+// with the fraction of elements less than or equal to it. The last fraction is 1.
+// It is computed in a single in-order walk, accumulating the run lengths of GroupEqual.
+//
+// The iterator follows the iter.Seq2 convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) CDF() func(yield func(T, float64) bool) {
+	return func(yield func(T, float64) bool) {
+		total := float64(t.root.size)
+		cumulative := 0
+		t.GroupEqual()(func(key T, count int) bool {
+			cumulative += count
+
+			return yield(key, float64(cumulative)/total)
+		})
+	}
+}
+
+// Leaves returns an iterator over the keys of leaf nodes, those without children,
+// in ascending order. In a single-node tree the root is a leaf.
+//
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) Leaves() func(yield func(T) bool) {
+	return t.nodesWhere(func(node *Node[T]) bool {
+		return node.left == t.nil && node.right == t.nil
+	})
+}
+
+// Internal returns an iterator over the keys of nodes with at least one child,
+// in ascending order. Together with Leaves it covers every element exactly once.
+//
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) Internal() func(yield func(T) bool) {
+	return t.nodesWhere(func(node *Node[T]) bool {
+		return node.left != t.nil || node.right != t.nil
+	})
+}
+
+// nodesWhere returns an iterator over the keys of nodes matching pred in ascending order
+func (t *Tree[T]) nodesWhere(pred func(*Node[T]) bool) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for node := t.first(); node != t.nil; node = t.successor(node) {
+			if pred(node) && !yield(node.key) {
+				return
+			}
+		}
+	}
+}
+
+// Chunks returns an iterator over successive slices of up to size keys
+// in ascending order; only the last chunk may be shorter. Each chunk is a newly
+// allocated slice that the caller may retain. Chunks panics if size is less than 1.
+//
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) Chunks(size int) func(yield func([]T) bool) {
+	if size < 1 {
+		panic("gostree: chunk size must be at least 1")
+	}
+
+	return func(yield func([]T) bool) {
+		remaining := t.root.size
+		node := t.first()
+		for remaining > 0 {
+			chunk := make([]T, 0, min(size, remaining))
+			for ; node != t.nil && len(chunk) < size; node = t.successor(node) {
+				chunk = append(chunk, node.key)
+			}
+			remaining -= len(chunk)
+			if !yield(chunk) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over all elements in descending order.
+// Stepping to the predecessor costs the same as stepping to the successor,
+// so iterating backward is as cheap as iterating forward.
+//
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) Backward() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for node := t.maximum(t.root); node != t.nil; node = t.predecessor(node) {
+			if !yield(node.key) {
+				return
+			}
+		}
+	}
+}
+
+// RangeHalfOpen returns an iterator over the keys k with lo <= k < hi in ascending
+// order. Unlike the inclusive AppendRange, keys equal to hi, including all of their
+// duplicates, are excluded. An empty or inverted range yields nothing.
+//
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) RangeHalfOpen(lo, hi T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for node := t.lowerBound(lo); node != t.nil && t.compare(node.key, hi) < 0; node = t.successor(node) {
+			if !yield(node.key) {
+				return
+			}
+		}
+	}
+}
+
+// GroupEqual returns an iterator over each run of equal keys in ascending order,
+// yielding the first key of the run and the number of elements in it.
+// It turns the multiset into a histogram in a single in-order walk.
+//
+// The iterator follows the iter.Seq2 convention and stops when yield returns false.
+// The tree must not be modified during iteration.
+func (t *Tree[T]) GroupEqual() func(yield func(T, int) bool) {
+	return func(yield func(T, int) bool) {
+		for node := t.first(); node != t.nil; {
+			key := node.key
+			count := 0
+			for node != t.nil && t.compare(key, node.key) == 0 {
+				count++
+				node = t.successor(node)
+			}
+			if !yield(key, count) {
+				return
+			}
+		}
+	}
+}
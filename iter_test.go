@@ -0,0 +1,388 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCDF(t *testing.T) {
+	t.Parallel()
+
+	type point struct {
+		key      int
+		fraction float64
+	}
+
+	collect := func(tree *Tree[int]) []point {
+		var points []point
+		tree.CDF()(func(key int, fraction float64) bool {
+			points = append(points, point{key: key, fraction: fraction})
+
+			return true
+		})
+
+		return points
+	}
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		if points := collect(buildTree(nil)); len(points) != 0 {
+			t.Errorf("CDF() yielded %v, want nothing", points)
+		}
+	})
+
+	t.Run("matches_manual_computation", func(t *testing.T) {
+		t.Parallel()
+
+		values := []int{5, 1, 3, 3, 5, 5, 9, 1}
+		tree := buildTree(values)
+
+		points := collect(tree)
+
+		distinct := []int{1, 3, 5, 9}
+		if len(points) != len(distinct) {
+			t.Fatalf("CDF() yielded %d points, want %d", len(points), len(distinct))
+		}
+		for i, key := range distinct {
+			lessOrEqual := 0
+			for _, v := range values {
+				if v <= key {
+					lessOrEqual++
+				}
+			}
+			want := point{key: key, fraction: float64(lessOrEqual) / float64(len(values))}
+			if points[i] != want {
+				t.Errorf("point %d = %+v, want %+v", i, points[i], want)
+			}
+		}
+	})
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4})
+
+		count := 0
+		tree.CDF()(func(int, float64) bool {
+			count++
+
+			return false
+		})
+
+		if count != 1 {
+			t.Errorf("yield called %d times, want 1", count)
+		}
+	})
+}
+
+func TestLeavesInternal(t *testing.T) {
+	t.Parallel()
+
+	collect := func(seq func(yield func(int) bool)) []int {
+		var keys []int
+		seq(func(key int) bool {
+			keys = append(keys, key)
+
+			return true
+		})
+
+		return keys
+	}
+
+	testCases := []struct {
+		name         string
+		values       []int
+		wantLeaves   []int
+		wantInternal []int
+	}{
+		{name: "empty", values: nil, wantLeaves: nil, wantInternal: nil},
+		{name: "single_node", values: []int{7}, wantLeaves: []int{7}, wantInternal: nil},
+		// 20(B) with children 10(B) and 30(B), 40(R) under 30
+		{name: "known_shape", values: []int{10, 20, 30, 40}, wantLeaves: []int{10, 40}, wantInternal: []int{20, 30}},
+		// 2(B) with children 1(R) and 3(R)
+		{name: "full_tree", values: []int{1, 2, 3}, wantLeaves: []int{1, 3}, wantInternal: []int{2}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree(tc.values)
+			if got := collect(tree.Leaves()); !slices.Equal(got, tc.wantLeaves) {
+				t.Errorf("Leaves() = %v, want %v", got, tc.wantLeaves)
+			}
+			if got := collect(tree.Internal()); !slices.Equal(got, tc.wantInternal) {
+				t.Errorf("Internal() = %v, want %v", got, tc.wantInternal)
+			}
+		})
+	}
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		var got []int
+		tree.Leaves()(func(key int) bool {
+			got = append(got, key)
+
+			return len(got) < 2
+		})
+		if len(got) != 2 {
+			t.Errorf("yielded %d keys, want 2", len(got))
+		}
+	})
+
+	t.Run("partition", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{50, 20, 80, 10, 30, 70, 90, 25, 35, 65, 5, 30})
+		all := append(collect(tree.Leaves()), collect(tree.Internal())...)
+		slices.Sort(all)
+		if want := tree.SortedSnapshot(); !slices.Equal(all, want) {
+			t.Errorf("Leaves() + Internal() = %v, want %v", all, want)
+		}
+	})
+}
+
+func TestChunks(t *testing.T) {
+	t.Parallel()
+
+	values := func(n int) []int {
+		out := make([]int, n)
+		for i := range out {
+			out[i] = i
+		}
+
+		return out
+	}
+
+	testCases := []struct {
+		name string
+		n    int
+		size int
+		want [][]int
+	}{
+		{name: "empty", n: 0, size: 3, want: nil},
+		{name: "exact_multiple", n: 6, size: 3, want: [][]int{{0, 1, 2}, {3, 4, 5}}},
+		{name: "remainder", n: 7, size: 3, want: [][]int{{0, 1, 2}, {3, 4, 5}, {6}}},
+		{name: "size_one", n: 3, size: 1, want: [][]int{{0}, {1}, {2}}},
+		{name: "larger_than_tree", n: 4, size: 10, want: [][]int{{0, 1, 2, 3}}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree(values(tc.n))
+			var got [][]int
+			tree.Chunks(tc.size)(func(chunk []int) bool {
+				got = append(got, chunk)
+
+				return true
+			})
+
+			if !slices.EqualFunc(got, tc.want, slices.Equal[[]int]) {
+				t.Errorf("Chunks(%d) = %v, want %v", tc.size, got, tc.want)
+			}
+		})
+	}
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree(values(10))
+		calls := 0
+		tree.Chunks(2)(func([]int) bool {
+			calls++
+
+			return false
+		})
+		if calls != 1 {
+			t.Errorf("yield called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("invalid_size_panics", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("Chunks(0) did not panic")
+			}
+		}()
+		buildTree(values(3)).Chunks(0)
+	})
+}
+
+func TestBackward(t *testing.T) {
+	t.Parallel()
+
+	collect := func(tree *Tree[int], limit int) []int {
+		var keys []int
+		tree.Backward()(func(key int) bool {
+			keys = append(keys, key)
+
+			return len(keys) < limit
+		})
+
+		return keys
+	}
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if got := collect(tree, 10); len(got) != 0 {
+			t.Errorf("Backward() yielded %v on empty tree", got)
+		}
+	})
+
+	t.Run("descending_order", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{5, 3, 8, 3, 1, 9, 7})
+		if got, want := collect(tree, 100), []int{9, 8, 7, 5, 3, 3, 1}; !slices.Equal(got, want) {
+			t.Errorf("Backward() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5})
+		if got, want := collect(tree, 2), []int{5, 4}; !slices.Equal(got, want) {
+			t.Errorf("Backward() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestRangeHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	tree := buildTree([]int{10, 20, 20, 30, 30, 30, 40})
+
+	testCases := []struct {
+		name   string
+		lo, hi int
+		want   []int
+	}{
+		{name: "hi_matches_duplicates", lo: 10, hi: 30, want: []int{10, 20, 20}},
+		{name: "lo_matches_duplicates", lo: 30, hi: 40, want: []int{30, 30, 30}},
+		{name: "between_keys", lo: 15, hi: 35, want: []int{20, 20, 30, 30, 30}},
+		{name: "covers_all", lo: 0, hi: 100, want: []int{10, 20, 20, 30, 30, 30, 40}},
+		{name: "empty_range", lo: 20, hi: 20, want: nil},
+		{name: "inverted_range", lo: 40, hi: 10, want: nil},
+		{name: "no_keys_inside", lo: 21, hi: 29, want: nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []int
+			tree.RangeHalfOpen(tc.lo, tc.hi)(func(key int) bool {
+				got = append(got, key)
+
+				return true
+			})
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("RangeHalfOpen(%d, %d) = %v, want %v", tc.lo, tc.hi, got, tc.want)
+			}
+		})
+	}
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		tree.RangeHalfOpen(0, 100)(func(int) bool {
+			calls++
+
+			return calls < 3
+		})
+		if calls != 3 {
+			t.Errorf("yield called %d times, want 3", calls)
+		}
+	})
+}
+
+func TestGroupEqual(t *testing.T) {
+	t.Parallel()
+
+	type group struct {
+		key, count int
+	}
+
+	collect := func(tree *Tree[int], limit int) []group {
+		var groups []group
+		tree.GroupEqual()(func(key, count int) bool {
+			groups = append(groups, group{key: key, count: count})
+
+			return len(groups) < limit
+		})
+
+		return groups
+	}
+
+	testCases := []struct {
+		name   string
+		values []int
+		want   []group
+	}{
+		{name: "empty", values: nil, want: nil},
+		{name: "distinct", values: []int{3, 1, 2}, want: []group{{1, 1}, {2, 1}, {3, 1}}},
+		{
+			name:   "duplicate_heavy",
+			values: []int{5, 5, 5, 5, 1, 5, 9, 9, 5, 1},
+			want:   []group{{1, 2}, {5, 6}, {9, 2}},
+		},
+		{name: "all_equal", values: []int{4, 4, 4, 4}, want: []group{{4, 4}}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := collect(buildTree(tc.values), 100); !slices.Equal(got, tc.want) {
+				t.Errorf("GroupEqual() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 1, 2, 2, 2, 3})
+		if got, want := collect(tree, 2), []group{{1, 2}, {2, 3}}; !slices.Equal(got, want) {
+			t.Errorf("GroupEqual() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("struct_keys_keep_first_payload", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct {
+			key     int
+			payload string
+		}
+		tree := NewTree(func(a, b item) int { return a.key - b.key })
+		for _, it := range []item{{1, "a"}, {2, "b"}, {1, "c"}} {
+			tree.Insert(it)
+		}
+
+		var keys []item
+		tree.GroupEqual()(func(key item, _ int) bool {
+			keys = append(keys, key)
+
+			return true
+		})
+		if want := []item{{1, "a"}, {2, "b"}}; !slices.Equal(keys, want) {
+			t.Errorf("GroupEqual() keys = %v, want %v", keys, want)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package gostree
+
+import "slices"
+
+// entry is a key-value pair stored in a Map
+type entry[K, V any] struct {
+	key   K
+	value V
+}
+
+// Map is an ordered map with unique keys, backed by an order-statistic tree.
+// Entries are kept sorted by key according to the comparison function.
+type Map[K, V any] struct {
+	tree      *Tree[entry[K, V]]
+	putPolicy PutPolicy
+}
+
+// MapOption configures optional behavior of a map created by NewMap.
+type MapOption[K, V any] func(*Map[K, V])
+
+// PutPolicy selects what Put does when the key is already present.
+type PutPolicy int
+
+const (
+	// ReplaceValue replaces the stored value (last write wins). It is the default.
+	ReplaceValue PutPolicy = iota
+	// KeepExisting keeps the stored value (first write wins).
+	KeepExisting
+)
+
+// WithPutPolicy sets how Put handles a key that is already present.
+func WithPutPolicy[K, V any](policy PutPolicy) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.putPolicy = policy
+	}
+}
+
+// NewMap creates a new ordered map with keys ordered by compare.
+func NewMap[K, V any](compare CompareFunc[K], opts ...MapOption[K, V]) *Map[K, V] {
+	m := &Map[K, V]{
+		tree: NewTree(func(a, b entry[K, V]) int {
+			return compare(a.key, b.key)
+		}),
+		putPolicy: ReplaceValue,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// NewMapFrom creates an ordered map holding the entries of m, with keys ordered
+// by compare. The keys are sorted in O(n log n) and the map is bulk-built in O(n).
+// If compare treats distinct keys of m as equal, only one of them is kept,
+// chosen arbitrarily since Go map iteration order is unspecified.
+func NewMapFrom[K comparable, V any](m map[K]V, compare CompareFunc[K], opts ...MapOption[K, V]) *Map[K, V] {
+	result := NewMap(compare, opts...)
+
+	entries := make([]entry[K, V], 0, len(m))
+	for key, value := range m {
+		entries = append(entries, entry[K, V]{key: key, value: value})
+	}
+	slices.SortFunc(entries, result.tree.compare)
+	entries = slices.CompactFunc(entries, func(a, b entry[K, V]) bool {
+		return compare(a.key, b.key) == 0
+	})
+	result.tree.buildSorted(entries)
+
+	return result
+}
+
+// Put associates value with key and reports whether the key was newly added.
+// If the key is already present, the stored value is replaced or kept
+// according to the map's PutPolicy.
+func (m *Map[K, V]) Put(key K, value V) bool {
+	if node := m.find(key); node != m.tree.nil {
+		switch m.putPolicy {
+		case ReplaceValue:
+			node.key.value = value
+		case KeepExisting:
+		}
+
+		return false
+	}
+	m.tree.Insert(entry[K, V]{key: key, value: value})
+
+	return true
+}
+
+// Get returns the value associated with key, or false if the key is absent.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	node := m.find(key)
+	if node == m.tree.nil {
+		var zero V
+
+		return zero, false
+	}
+
+	return node.key.value, true
+}
+
+// Delete removes key and its value from the map and reports whether it was present.
+func (m *Map[K, V]) Delete(key K) bool {
+	node := m.find(key)
+	if node == m.tree.nil {
+		return false
+	}
+	m.tree.deleteNode(node)
+
+	return true
+}
+
+// UpdateValues replaces the value of every entry whose key satisfies pred with
+// f applied to the old value and returns the number of entries updated.
+// Keys are visited in ascending order and left untouched, so the tree is not
+// rebalanced. pred and f must not modify the map.
+func (m *Map[K, V]) UpdateValues(pred func(K) bool, f func(V) V) int {
+	updated := 0
+	for node := m.tree.first(); node != m.tree.nil; node = m.tree.successor(node) {
+		if pred(node.key.key) {
+			node.key.value = f(node.key.value)
+			updated++
+		}
+	}
+
+	return updated
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	return m.tree.Size()
+}
+
+// All returns an iterator over the entries in ascending key order.
+//
+// The iterator follows the iter.Seq2 convention and stops when yield returns false.
+// The map must not be modified during iteration.
+func (m *Map[K, V]) All() func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		for node := m.tree.first(); node != m.tree.nil; node = m.tree.successor(node) {
+			if !yield(node.key.key, node.key.value) {
+				return
+			}
+		}
+	}
+}
+
+// ToSortedKeys returns the keys of the map in ascending order.
+func (m *Map[K, V]) ToSortedKeys() []K {
+	keys := make([]K, 0, m.tree.Size())
+	for node := m.tree.first(); node != m.tree.nil; node = m.tree.successor(node) {
+		keys = append(keys, node.key.key)
+	}
+
+	return keys
+}
+
+// ToGoMap returns a newly allocated Go map holding the entries of m.
+// The key order of m is lost; use ToSortedKeys alongside it when order matters.
+// It is a function rather than a method because it requires comparable keys,
+// the counterpart of NewMapFrom.
+func ToGoMap[K comparable, V any](m *Map[K, V]) map[K]V {
+	result := make(map[K]V, m.tree.Size())
+	for node := m.tree.first(); node != m.tree.nil; node = m.tree.successor(node) {
+		result[node.key.key] = node.key.value
+	}
+
+	return result
+}
+
+// find returns the node holding key, or the sentinel if there is none
+func (m *Map[K, V]) find(key K) *Node[entry[K, V]] {
+	var probe entry[K, V]
+	probe.key = key
+
+	return m.tree.search(probe)
+}
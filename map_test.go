@@ -0,0 +1,267 @@
+package gostree
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func collectMap[K, V any](m *Map[K, V]) ([]K, []V) {
+	var keys []K
+	var values []V
+	m.All()(func(key K, value V) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+
+		return true
+	})
+
+	return keys, values
+}
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("put_get_delete", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMap[int, string](func(a, b int) int { return a - b })
+		for _, key := range []int{30, 10, 20} {
+			if !m.Put(key, "v") {
+				t.Errorf("Put(%d) = false for new key, want true", key)
+			}
+		}
+		if m.Len() != 3 {
+			t.Errorf("Len() = %d, want 3", m.Len())
+		}
+
+		if value, ok := m.Get(20); !ok || value != "v" {
+			t.Errorf("Get(20) = %q, %v, want \"v\", true", value, ok)
+		}
+		if _, ok := m.Get(25); ok {
+			t.Error("Get(25) ok = true for absent key, want false")
+		}
+
+		if !m.Delete(20) || m.Delete(20) {
+			t.Error("Delete(20) should succeed once")
+		}
+		if _, ok := m.Get(20); ok || m.Len() != 2 {
+			t.Errorf("after Delete(20): ok = %v, Len() = %d, want false, 2", ok, m.Len())
+		}
+		checkRedBlackProperties(t, m.tree)
+	})
+
+	t.Run("ordered_iteration", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMap[string, int](func(a, b string) int {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			default:
+				return 0
+			}
+		})
+		for i, key := range []string{"pear", "apple", "fig", "banana"} {
+			m.Put(key, i)
+		}
+
+		keys, values := collectMap(m)
+		wantKeys := []string{"apple", "banana", "fig", "pear"}
+		wantValues := []int{1, 3, 2, 0}
+		for i := range wantKeys {
+			if keys[i] != wantKeys[i] || values[i] != wantValues[i] {
+				t.Errorf("entry %d = %s:%d, want %s:%d", i, keys[i], values[i], wantKeys[i], wantValues[i])
+			}
+		}
+	})
+}
+
+func TestMapPutPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		opts []MapOption[int, string]
+		want string
+	}{
+		{name: "default_replaces", opts: nil, want: "second"},
+		{name: "replace_value", opts: []MapOption[int, string]{WithPutPolicy[int, string](ReplaceValue)}, want: "second"},
+		{name: "keep_existing", opts: []MapOption[int, string]{WithPutPolicy[int, string](KeepExisting)}, want: "first"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewMap(func(a, b int) int { return a - b }, tc.opts...)
+			if !m.Put(1, "first") {
+				t.Error("first Put() = false, want true")
+			}
+			if m.Put(1, "second") {
+				t.Error("second Put() = true for existing key, want false")
+			}
+
+			if got, _ := m.Get(1); got != tc.want {
+				t.Errorf("Get(1) = %q, want %q", got, tc.want)
+			}
+			if m.Len() != 1 {
+				t.Errorf("Len() = %d, want 1", m.Len())
+			}
+		})
+	}
+}
+
+func TestMapUpdateValues(t *testing.T) {
+	t.Parallel()
+
+	m := NewMap[int, int](func(a, b int) int { return a - b })
+	for key := 1; key <= 10; key++ {
+		m.Put(key, key*10)
+	}
+
+	var visited []int
+	updated := m.UpdateValues(func(key int) bool {
+		visited = append(visited, key)
+
+		return key%3 == 0
+	}, func(value int) int { return value + 1 })
+
+	if updated != 3 {
+		t.Errorf("UpdateValues() = %d, want 3", updated)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}; !slices.Equal(visited, want) {
+		t.Errorf("visited keys %v, want %v", visited, want)
+	}
+
+	keys, values := collectMap(m)
+	wantValues := []int{10, 20, 31, 40, 50, 61, 70, 80, 91, 100}
+	if !slices.Equal(keys, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) || !slices.Equal(values, wantValues) {
+		t.Errorf("entries = %v, %v, want values %v", keys, values, wantValues)
+	}
+
+	if got := m.UpdateValues(func(int) bool { return false }, func(v int) int { return -v }); got != 0 {
+		t.Errorf("UpdateValues() with no match = %d, want 0", got)
+	}
+	checkRedBlackProperties(t, m.tree)
+}
+
+func TestNewMapFrom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_map", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMapFrom(map[int]string{}, func(a, b int) int { return a - b })
+		if m.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", m.Len())
+		}
+		m.Put(1, "one")
+		if value, ok := m.Get(1); !ok || value != "one" {
+			t.Errorf("Get(1) = %q, %v, want \"one\", true", value, ok)
+		}
+	})
+
+	t.Run("ordered_and_retrievable", func(t *testing.T) {
+		t.Parallel()
+
+		source := make(map[int]string)
+		for i := 0; i < 100; i++ {
+			source[(i*37)%100] = string(rune('a' + i%26))
+		}
+
+		m := NewMapFrom(source, func(a, b int) int { return a - b })
+		if m.Len() != len(source) {
+			t.Fatalf("Len() = %d, want %d", m.Len(), len(source))
+		}
+
+		keys, values := collectMap(m)
+		for i, key := range keys {
+			if key != i {
+				t.Fatalf("key %d = %d, want ascending order", i, key)
+			}
+			if values[i] != source[key] {
+				t.Errorf("value for %d = %q, want %q", key, values[i], source[key])
+			}
+			if value, ok := m.Get(key); !ok || value != source[key] {
+				t.Errorf("Get(%d) = %q, %v, want %q, true", key, value, ok, source[key])
+			}
+		}
+		checkRedBlackProperties(t, m.tree)
+		verifySizes(t, m.tree.root, m.tree.nil)
+	})
+
+	t.Run("keys_equal_under_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		// Keys are compared by their value modulo 10, so 3, 13 and 23 collide
+		source := map[int]int{3: 3, 13: 13, 23: 23, 5: 5}
+		m := NewMapFrom(source, func(a, b int) int { return a%10 - b%10 })
+
+		if m.Len() != 2 {
+			t.Errorf("Len() = %d, want 2", m.Len())
+		}
+		if value, ok := m.Get(3); !ok || value%10 != 3 {
+			t.Errorf("Get(3) = %d, %v, want one of the colliding entries", value, ok)
+		}
+	})
+
+	t.Run("options_apply", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMapFrom(map[int]string{1: "first"}, func(a, b int) int { return a - b },
+			WithPutPolicy[int, string](KeepExisting))
+		m.Put(1, "second")
+		if value, _ := m.Get(1); value != "first" {
+			t.Errorf("Get(1) = %q, want \"first\"", value)
+		}
+	})
+}
+
+func TestToGoMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round_trip", func(t *testing.T) {
+		t.Parallel()
+
+		source := map[string]int{"pear": 4, "apple": 1, "fig": 3, "banana": 2}
+		m := NewMapFrom(source, func(a, b string) int {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			default:
+				return 0
+			}
+		})
+
+		got := ToGoMap(m)
+		if !maps.Equal(got, source) {
+			t.Errorf("ToGoMap() = %v, want %v", got, source)
+		}
+		got["kiwi"] = 5
+		if m.Len() != len(source) {
+			t.Error("modifying the result of ToGoMap changed the map")
+		}
+
+		if keys, want := m.ToSortedKeys(), []string{"apple", "banana", "fig", "pear"}; !slices.Equal(keys, want) {
+			t.Errorf("ToSortedKeys() = %v, want %v", keys, want)
+		}
+	})
+
+	t.Run("empty_map", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMap[int, int](func(a, b int) int { return a - b })
+		if got := ToGoMap(m); got == nil || len(got) != 0 {
+			t.Errorf("ToGoMap() = %v, want empty non-nil map", got)
+		}
+		if keys := m.ToSortedKeys(); len(keys) != 0 {
+			t.Errorf("ToSortedKeys() = %v, want empty", keys)
+		}
+	})
+}
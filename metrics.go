@@ -0,0 +1,63 @@
+package gostree
+
+import "sync/atomic"
+
+// OpMetrics is a snapshot of the cumulative operation counters of a tree
+// created with WithMetrics.
+type OpMetrics struct {
+	Comparisons    uint64 // calls of the comparison function
+	LeftRotations  uint64 // left rotations performed while rebalancing
+	RightRotations uint64 // right rotations performed while rebalancing
+	Inserts        uint64 // elements inserted one at a time, excluding bulk rebuilds
+	Deletes        uint64 // elements deleted one at a time, excluding bulk rebuilds
+	Searches       uint64 // calls of Search
+}
+
+// opCounters holds the live counters behind OpMetrics
+type opCounters struct {
+	comparisons    atomic.Uint64
+	leftRotations  atomic.Uint64
+	rightRotations atomic.Uint64
+	inserts        atomic.Uint64
+	deletes        atomic.Uint64
+	searches       atomic.Uint64
+}
+
+// WithMetrics enables cumulative operation counters available via Metrics,
+// e.g. to assert in regression tests that an insert pattern stays within
+// a rotation budget. Trees created without this option pay only a nil check
+// per counted event.
+func WithMetrics[T any]() Option[T] {
+	return func(t *Tree[T]) {
+		counters := new(opCounters)
+		t.metrics = counters
+
+		if t.compare == nil {
+			return
+		}
+		compare := t.compare
+		t.compare = func(a, b T) int {
+			counters.comparisons.Add(1)
+
+			return compare(a, b)
+		}
+	}
+}
+
+// Metrics returns a snapshot of the operation counters since the tree was created,
+// or zero values if WithMetrics is not set.
+func (t *Tree[T]) Metrics() OpMetrics {
+	var metrics OpMetrics
+	if t.metrics == nil {
+		return metrics
+	}
+
+	return OpMetrics{
+		Comparisons:    t.metrics.comparisons.Load(),
+		LeftRotations:  t.metrics.leftRotations.Load(),
+		RightRotations: t.metrics.rightRotations.Load(),
+		Inserts:        t.metrics.inserts.Load(),
+		Deletes:        t.metrics.deletes.Load(),
+		Searches:       t.metrics.searches.Load(),
+	}
+}
@@ -0,0 +1,128 @@
+package gostree
+
+import (
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		tree.Search(2)
+		var zero OpMetrics
+		if got := tree.Metrics(); got != zero {
+			t.Errorf("Metrics() = %+v without WithMetrics, want zero", got)
+		}
+	})
+
+	t.Run("known_sequence", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMetrics[int]())
+
+		// Inserting 1..7 rotates left at 3, 5 and 7; 4 and 6 only recolor
+		for i := 1; i <= 7; i++ {
+			tree.Insert(i)
+		}
+		// Inserting 0 below 1 recolors only; the tree stays balanced
+		tree.Insert(0)
+
+		tree.Search(4)
+		tree.Search(100)
+		tree.Delete(0)
+		tree.Delete(100)
+
+		got := tree.Metrics()
+		if got.LeftRotations != 3 || got.RightRotations != 0 {
+			t.Errorf("rotations = %d left, %d right, want 3 left, 0 right", got.LeftRotations, got.RightRotations)
+		}
+		if got.Inserts != 8 || got.Deletes != 1 || got.Searches != 2 {
+			t.Errorf("Inserts, Deletes, Searches = %d, %d, %d, want 8, 1, 2", got.Inserts, got.Deletes, got.Searches)
+		}
+		if got.Comparisons == 0 {
+			t.Error("Comparisons = 0, want > 0")
+		}
+	})
+
+	t.Run("decreasing_inserts_rotate_right", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMetrics[int]())
+		for i := 7; i >= 1; i-- {
+			tree.Insert(i)
+		}
+
+		if got := tree.Metrics(); got.LeftRotations != 0 || got.RightRotations != 3 {
+			t.Errorf("rotations = %d left, %d right, want 0 left, 3 right", got.LeftRotations, got.RightRotations)
+		}
+	})
+
+	t.Run("counts_comparisons", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMetrics[int](), WithComparatorCounter[int]())
+		for _, v := range []int{5, 3, 8, 1, 4} {
+			tree.Insert(v)
+		}
+		tree.Search(4)
+
+		if got, want := tree.Metrics().Comparisons, tree.ComparatorCalls(); got != want {
+			t.Errorf("Comparisons = %d, want %d as counted by WithComparatorCounter", got, want)
+		}
+	})
+
+	t.Run("sorted_append_inserts", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMetrics[int](), WithSortedAppend[int]())
+		for i := 0; i < 10; i++ {
+			tree.Insert(i)
+		}
+		_ = tree.InsertAscending(10)
+
+		if got := tree.Metrics(); got.Inserts != 11 || got.Comparisons != 10 {
+			t.Errorf("Inserts, Comparisons = %d, %d, want 11, 10", got.Inserts, got.Comparisons)
+		}
+	})
+	t.Run("derived_trees_independent", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMetrics[int](), WithComparatorCounter[int]())
+		for i := 0; i < 10; i++ {
+			tree.Insert(i)
+		}
+
+		derived := map[string]*Tree[int]{
+			"clone":           tree.Clone(),
+			"union_all":       UnionAll(tree, tree),
+			"union_stable":    UnionStable(tree, tree),
+			"subtree_by_rank": tree.SubtreeByRank(2, 8),
+		}
+		// UnionAll and UnionStable compare while merging, using the comparator of the source
+		before, calls := tree.Metrics(), tree.ComparatorCalls()
+
+		for name, d := range derived {
+			var zero OpMetrics
+			if got := d.Metrics(); got != zero {
+				t.Errorf("%s: Metrics() = %+v right after creation, want zero", name, got)
+			}
+			for i := 100; i < 200; i++ {
+				d.Insert(i)
+			}
+			d.Search(150)
+			if got := d.Metrics(); got.Inserts != 100 || got.Searches != 1 || got.Comparisons != d.ComparatorCalls() {
+				t.Errorf("%s: Metrics() = %+v, want 100 inserts and 1 search", name, got)
+			}
+		}
+
+		if got := tree.Metrics(); got != before {
+			t.Errorf("source Metrics() = %+v after using derived trees, want %+v", got, before)
+		}
+		if got := tree.ComparatorCalls(); got != calls {
+			t.Errorf("source ComparatorCalls() = %d after using derived trees, want %d", got, calls)
+		}
+	})
+}
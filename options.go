@@ -0,0 +1,270 @@
+package gostree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync/atomic"
+)
+
+// Option configures optional behavior of a tree created by NewTree.
+type Option[T any] func(*Tree[T])
+
+// WithComparatorCounter counts invocations of the comparison function.
+// The count is available via ComparatorCalls and is intended for profiling
+// expensive comparators. Trees created without this option pay no overhead.
+func WithComparatorCounter[T any]() Option[T] {
+	return func(t *Tree[T]) {
+		if t.compare == nil {
+			return
+		}
+
+		calls := &atomic.Uint64{}
+		compare := t.compare
+		t.compareCalls = calls
+		t.compare = func(a, b T) int {
+			calls.Add(1)
+
+			return compare(a, b)
+		}
+	}
+}
+
+// ComparatorCalls returns the number of comparison function invocations
+// since the tree was created, or 0 if WithComparatorCounter is not set.
+func (t *Tree[T]) ComparatorCalls() uint64 {
+	if t.compareCalls == nil {
+		return 0
+	}
+
+	return t.compareCalls.Load()
+}
+
+// EvictPolicy selects which element a bounded tree evicts when it is full.
+type EvictPolicy int
+
+const (
+	// EvictSmallest evicts the element with the smallest key.
+	EvictSmallest EvictPolicy = iota
+	// EvictLargest evicts the element with the largest key.
+	EvictLargest
+)
+
+// WithMaxSize bounds the tree to at most n elements. When an insert would exceed
+// the bound, the element selected by evict is removed first, so the inserted key
+// is always retained. Operations that build a tree in bulk, such as Reset, AddFrom,
+// UnionAll, UnionStable, GroupBy and SubtreeByRank, keep only the elements the
+// policy would retain. A non-positive n leaves the tree unbounded.
+// It replaces any earlier WithReservoir: of the two options, the last one applied wins.
+func WithMaxSize[T any](n int, evict EvictPolicy) Option[T] {
+	return func(t *Tree[T]) {
+		if n <= 0 {
+			return
+		}
+		t.maxSize = n
+		t.evictPolicy = evict
+		t.reservoir = nil
+		t.sampleSize = 0
+	}
+}
+
+// evict removes and returns the element selected by the eviction policy
+func (t *Tree[T]) evict() (T, bool) {
+	if t.root == t.nil {
+		var zero T
+
+		return zero, false
+	}
+
+	var node *Node[T]
+	switch t.evictPolicy {
+	case EvictSmallest:
+		node = t.minimum(t.root)
+	case EvictLargest:
+		node = t.maximum(t.root)
+	}
+	key := node.key
+	t.deleteNode(node)
+
+	return key, true
+}
+
+// WithReservoir bounds the tree to at most n elements that form a uniform random
+// sample of all keys passed to Insert, using reservoir sampling with r as the
+// source of randomness. Until the tree holds n elements every key is inserted;
+// after that, the k-th key offered replaces a random element with probability n/k
+// and is dropped otherwise, so memory stays bounded however long the stream is.
+// A nil r gives each tree its own generator seeded from the math/rand global source.
+// A non-nil r is shared by every tree the option is applied to, including those
+// derived by Clone, UnionAll, UnionStable, GroupBy and SubtreeByRank; since a
+// *rand.Rand is not safe for concurrent use, such trees must not be used from
+// different goroutines at the same time.
+//
+// Select, Rank, Quantiles and the other order statistics then describe the sample,
+// not the stream: they become approximate quantile estimators whose error shrinks
+// with n, and exact queries such as Search or Size no longer reflect every key
+// inserted. Insert, InsertEvict and InsertAscending sample each key, and Reset
+// samples its values; Drain and Reset start a new stream. Other bulk operations and
+// deletions are not accounted for. A non-positive n leaves the tree unbounded.
+//
+// It replaces any earlier WithMaxSize: of the two options, the last one applied wins.
+func WithReservoir[T any](n int, r *rand.Rand) Option[T] {
+	return func(t *Tree[T]) {
+		if n <= 0 {
+			return
+		}
+		rng := r
+		if rng == nil {
+			rng = rand.New(rand.NewSource(rand.Int63()))
+		}
+		t.maxSize = 0
+		t.sampleSize = n
+		t.reservoir = rng
+	}
+}
+
+// sample inserts key according to reservoir sampling and reports the key that
+// left the sample, which is either a replaced element or key itself
+func (t *Tree[T]) sample(key T) (T, bool) {
+	var evicted T
+	t.offered++
+	if t.root.size < t.sampleSize {
+		t.insert(key)
+
+		return evicted, false
+	}
+
+	slot := t.reservoir.Intn(t.offered)
+	if slot >= t.sampleSize {
+		return key, true
+	}
+
+	node := t.selectNode(t.root, slot)
+	evicted = node.key
+	t.deleteNode(node)
+	t.insert(key)
+
+	return evicted, true
+}
+
+// sampleOf returns a uniform random sample of sampleSize values, drawn with
+// the same algorithm as sample so that Reset matches inserting values one by one
+func (t *Tree[T]) sampleOf(values []T) []T {
+	sampled := slices.Clone(values[:t.sampleSize])
+	for i := t.sampleSize; i < len(values); i++ {
+		if slot := t.reservoir.Intn(i + 1); slot < t.sampleSize {
+			sampled[slot] = values[i]
+		}
+	}
+
+	return sampled
+}
+
+// WithComparatorChecks enables a debug mode in which every comparison made by the tree
+// also evaluates the arguments in reverse order and panics if compare(a, b) and
+// compare(b, a) do not have opposite signs. It helps diagnose custom comparators
+// that are not a consistent total order, at the cost of doubling comparison work.
+func WithComparatorChecks[T any]() Option[T] {
+	return func(t *Tree[T]) {
+		if t.compare == nil {
+			return
+		}
+
+		compare := t.compare
+		t.compare = func(a, b T) int {
+			forward, backward := compare(a, b), compare(b, a)
+			if sign(forward) != -sign(backward) {
+				panic(fmt.Sprintf("gostree: inconsistent comparator: compare(%v, %v) = %d but compare(%v, %v) = %d",
+					a, b, forward, b, a, backward))
+			}
+
+			return forward
+		}
+	}
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// WithCodec sets the functions used by MarshalBinary and UnmarshalBinary
+// to convert keys to and from bytes.
+func WithCodec[T any](encode func(T) []byte, decode func([]byte) (T, error)) Option[T] {
+	return func(t *Tree[T]) {
+		t.encodeKey = encode
+		t.decodeKey = decode
+	}
+}
+
+// RotationKind identifies the direction of a rotation reported by WithRotationHook.
+type RotationKind int
+
+const (
+	// RotateLeft is a left rotation: the pivot's right child takes its place.
+	RotateLeft RotationKind = iota
+	// RotateRight is a right rotation: the pivot's left child takes its place.
+	RotateRight
+)
+
+// WithRotationHook calls hook before every rotation performed while rebalancing,
+// with the rotation direction and the key of the pivot node that moves down.
+// It is intended for instrumentation and teaching; trees created without
+// this option pay only a nil check per rotation.
+func WithRotationHook[T any](hook func(kind RotationKind, pivotKey T)) Option[T] {
+	return func(t *Tree[T]) {
+		t.rotationHook = hook
+	}
+}
+
+// FixupPhase identifies the rebalancing pass reported by WithFixupHook.
+type FixupPhase int
+
+const (
+	// FixupInsert is the rebalancing pass that follows an insertion.
+	FixupInsert FixupPhase = iota
+	// FixupDelete is the rebalancing pass that follows a deletion.
+	FixupDelete
+)
+
+// WithFixupHook calls hook each time a red-black fixup case is applied, with the
+// pass, the case number and the key of the node being fixed. Insertion reports
+// cases 1 to 3 (red uncle, inner child, outer child) and deletion cases 1 to 4
+// (red sibling, black nephews, near red nephew, far red nephew), numbered as in
+// the comments of insertFixup and deleteFixup; mirrored cases share a number.
+// During deletion the node being fixed may be an empty leaf, reported with the
+// zero key. It is intended for visualizations and teaching; trees created
+// without this option pay only a nil check per case.
+func WithFixupHook[T any](hook func(phase FixupPhase, caseNumber int, nodeKey T)) Option[T] {
+	return func(t *Tree[T]) {
+		t.fixupHook = hook
+	}
+}
+
+// WithSortedAppend optimizes the tree for keys that mostly arrive in non-decreasing
+// order, such as event logs. The tree caches its maximum node, and an inserted key
+// that is not less than the maximum is attached directly as the rightmost node after
+// a single comparison, skipping the search descent. Keys that arrive out of order
+// fall back to a regular insert; use InsertAscending to reject them instead.
+func WithSortedAppend[T any]() Option[T] {
+	return func(t *Tree[T]) {
+		t.sortedAppend = true
+	}
+}
+
+// WithEquality sets an identity check used by Search and Delete in addition to the
+// ordering. Among the elements that compare equal to the key, only those for which
+// eq(key, element) holds are considered a match, so struct keys ordered by one field
+// but identified by several can be told apart. The run of comparator-equal elements
+// is scanned linearly. Without this option, compare(a, b) == 0 means equal.
+func WithEquality[T any](eq func(a, b T) bool) Option[T] {
+	return func(t *Tree[T]) {
+		t.equal = eq
+	}
+}
@@ -0,0 +1,744 @@
+package gostree
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithComparatorCounter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		tree.Search(2)
+
+		if calls := tree.ComparatorCalls(); calls != 0 {
+			t.Errorf("ComparatorCalls() = %d, want 0", calls)
+		}
+	})
+
+	t.Run("counts_calls", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithComparatorCounter[int]())
+		tree.Insert(10) // empty tree: no comparisons
+		if calls := tree.ComparatorCalls(); calls != 0 {
+			t.Errorf("after first insert: ComparatorCalls() = %d, want 0", calls)
+		}
+
+		tree.Insert(20) // one comparison on the way down, one to attach
+		if calls := tree.ComparatorCalls(); calls != 2 {
+			t.Errorf("after second insert: ComparatorCalls() = %d, want 2", calls)
+		}
+
+		tree.Search(20)
+		if calls := tree.ComparatorCalls(); calls != 4 {
+			t.Errorf("after search: ComparatorCalls() = %d, want 4", calls)
+		}
+	})
+
+	t.Run("search_is_logarithmic", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithComparatorCounter[int]())
+		for i := 0; i < 1023; i++ {
+			tree.Insert(i)
+		}
+
+		// A red-black tree with n nodes has height at most 2*log2(n+1)
+		const maxComparisons = 20
+		for _, key := range []int{0, 511, 1022, -1, 5000} {
+			before := tree.ComparatorCalls()
+			tree.Search(key)
+			calls := tree.ComparatorCalls() - before
+
+			if calls == 0 || calls > maxComparisons {
+				t.Errorf("Search(%d) made %d comparisons, want between 1 and %d", key, calls, maxComparisons)
+			}
+		}
+	})
+
+	t.Run("nil_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](nil, WithComparatorCounter[int]())
+		if tree.compare != nil {
+			t.Error("nil comparator should not be wrapped")
+		}
+	})
+}
+
+func TestWithMaxSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evict_smallest", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](3, EvictSmallest))
+		for _, v := range []int{50, 10, 30, 40, 20} {
+			tree.Insert(v)
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		}
+
+		// 50, 10, 30 -> insert 40 evicts 10 -> insert 20 evicts 30
+		if got := tree.SortedSnapshot(); len(got) != 3 || got[0] != 20 || got[1] != 40 || got[2] != 50 {
+			t.Errorf("elements = %v, want [20 40 50]", got)
+		}
+	})
+
+	t.Run("evict_largest", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](3, EvictLargest))
+		for _, v := range []int{50, 10, 30, 40, 20} {
+			tree.Insert(v)
+		}
+
+		// 50, 10, 30 -> insert 40 evicts 50 -> insert 20 evicts 40
+		if got := tree.SortedSnapshot(); len(got) != 3 || got[0] != 10 || got[1] != 20 || got[2] != 30 {
+			t.Errorf("elements = %v, want [10 20 30]", got)
+		}
+	})
+
+	t.Run("insert_evict_reports_evicted", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](2, EvictSmallest))
+
+		if _, ok := tree.InsertEvict(5); ok {
+			t.Error("InsertEvict(5) should not evict below capacity")
+		}
+		if _, ok := tree.InsertEvict(7); ok {
+			t.Error("InsertEvict(7) should not evict at capacity")
+		}
+		if evicted, ok := tree.InsertEvict(6); !ok || evicted != 5 {
+			t.Errorf("InsertEvict(6) = %d, %v; want 5, true", evicted, ok)
+		}
+		if size := tree.Size(); size != 2 {
+			t.Errorf("Size() = %d, want 2", size)
+		}
+	})
+
+	t.Run("unbounded_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](0, EvictSmallest))
+		for i := 0; i < 100; i++ {
+			if _, ok := tree.InsertEvict(i); ok {
+				t.Fatalf("InsertEvict(%d) should not evict from unbounded tree", i)
+			}
+		}
+		if size := tree.Size(); size != 100 {
+			t.Errorf("Size() = %d, want 100", size)
+		}
+	})
+
+	t.Run("bulk_operations", func(t *testing.T) {
+		t.Parallel()
+
+		newBounded := func(evict EvictPolicy, values ...int) *Tree[int] {
+			tree := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](3, evict))
+			for _, v := range values {
+				tree.Insert(v)
+			}
+
+			return tree
+		}
+		other := buildTree([]int{4, 5, 6})
+
+		testCases := []struct {
+			name  string
+			build func() *Tree[int]
+			want  []int
+		}{
+			{name: "add_from", build: func() *Tree[int] {
+				tree := newBounded(EvictSmallest, 1, 2, 3)
+				tree.AddFrom(other)
+
+				return tree
+			}, want: []int{4, 5, 6}},
+			{name: "add_from_evict_largest", build: func() *Tree[int] {
+				tree := newBounded(EvictLargest, 1, 5, 9)
+				tree.AddFrom(other)
+
+				return tree
+			}, want: []int{1, 4, 5}},
+			{name: "union_all", build: func() *Tree[int] {
+				return UnionAll(newBounded(EvictSmallest, 1, 2, 3), other)
+			}, want: []int{4, 5, 6}},
+			{name: "union_stable", build: func() *Tree[int] {
+				return UnionStable(newBounded(EvictLargest, 1, 2, 3), other)
+			}, want: []int{1, 2, 3}},
+			{name: "subtree_by_rank", build: func() *Tree[int] {
+				return newBounded(EvictSmallest, 1, 2, 3).SubtreeByRank(0, 2)
+			}, want: []int{1, 2}},
+			{name: "reset", build: func() *Tree[int] {
+				tree := newBounded(EvictSmallest)
+				tree.Reset([]int{9, 1, 8, 2, 7})
+
+				return tree
+			}, want: []int{7, 8, 9}},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				tree := tc.build()
+				if got := tree.SortedSnapshot(); !slices.Equal(got, tc.want) {
+					t.Errorf("elements = %v, want %v", got, tc.want)
+				}
+				checkRedBlackProperties(t, tree)
+				verifySizes(t, tree.root, tree.nil)
+
+				tree.Insert(100)
+				if tree.Size() > 3 {
+					t.Errorf("Size() = %d after Insert, want at most 3", tree.Size())
+				}
+			})
+		}
+	})
+}
+
+func TestWithReservoir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("estimates_percentiles", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			sampleSize = 2000
+			streamSize = 200000
+		)
+
+		// The stream is a shuffled permutation of 0..streamSize-1, so the p-th
+		// percentile of the stream is p*streamSize/100
+		r := rand.New(rand.NewSource(1))
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithReservoir[int](sampleSize, rand.New(rand.NewSource(2))))
+		for _, v := range r.Perm(streamSize) {
+			tree.Insert(v)
+		}
+
+		if tree.Size() != sampleSize {
+			t.Fatalf("Size() = %d, want %d", tree.Size(), sampleSize)
+		}
+		for _, p := range []int{1, 10, 25, 50, 75, 90, 99} {
+			estimate, _ := tree.Select(tree.Size() * p / 100)
+			exact := streamSize * p / 100
+			if diff := abs(estimate - exact); diff > 3*streamSize/100 {
+				t.Errorf("P%d estimate = %d, exact %d, off by %d", p, estimate, exact, diff)
+			}
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("insert_evict_reports_dropped", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithReservoir[int](3, rand.New(rand.NewSource(3))))
+		for i := 0; i < 3; i++ {
+			if _, ok := tree.InsertEvict(i); ok {
+				t.Fatalf("InsertEvict(%d) evicted before the reservoir was full", i)
+			}
+		}
+
+		offered := []int{0, 1, 2}
+		var left []int
+		for i := 3; i < 100; i++ {
+			offered = append(offered, i)
+			evicted, ok := tree.InsertEvict(i)
+			if !ok {
+				t.Fatalf("InsertEvict(%d) ok = false on a full reservoir", i)
+			}
+			left = append(left, evicted)
+		}
+
+		// Every offered key is either still sampled or was reported exactly once
+		got := append(tree.SortedSnapshot(), left...)
+		slices.Sort(got)
+		if !slices.Equal(got, offered) {
+			t.Errorf("sample plus reported keys = %v, want %v", got, offered)
+		}
+	})
+
+	// inclusion returns how often each of the keys 0..9 ends up in a reservoir of 3
+	// over many trials, where fill offers the keys to a fresh tree created with opts
+	// followed by WithReservoir
+	inclusion := func(t *testing.T, fill func(tree *Tree[int]), opts ...Option[int]) [10]int {
+		t.Helper()
+
+		var counts [10]int
+		for seed := int64(0); seed < 3000; seed++ {
+			tree := NewTree[int](func(a, b int) int { return a - b },
+				append(opts, WithReservoir[int](3, rand.New(rand.NewSource(seed))))...)
+			fill(tree)
+			if tree.Size() != 3 {
+				t.Fatalf("Size() = %d, want 3", tree.Size())
+			}
+			for _, v := range tree.SortedSnapshot() {
+				counts[v]++
+			}
+		}
+
+		return counts
+	}
+
+	// Every key should be sampled in about 3/10 of the 3000 trials
+	checkUniform := func(t *testing.T, counts [10]int) {
+		t.Helper()
+
+		for v, count := range counts {
+			if count < 800 || count > 1000 {
+				t.Errorf("key %d sampled %d times in 3000 trials, want about 900", v, count)
+			}
+		}
+	}
+
+	keys := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	insertAll := func(tree *Tree[int]) {
+		for _, v := range keys {
+			tree.Insert(v)
+		}
+	}
+
+	t.Run("insert_ascending_samples", func(t *testing.T) {
+		t.Parallel()
+
+		checkUniform(t, inclusion(t, func(tree *Tree[int]) {
+			for _, v := range keys {
+				if err := tree.InsertAscending(v); err != nil {
+					t.Fatalf("InsertAscending(%d) error = %v", v, err)
+				}
+			}
+		}))
+	})
+
+	t.Run("reset_samples", func(t *testing.T) {
+		t.Parallel()
+
+		checkUniform(t, inclusion(t, func(tree *Tree[int]) {
+			tree.Reset(keys)
+		}))
+	})
+
+	t.Run("drain_restarts_stream", func(t *testing.T) {
+		t.Parallel()
+
+		checkUniform(t, inclusion(t, func(tree *Tree[int]) {
+			for v := 100; v < 1100; v++ {
+				tree.Insert(v)
+			}
+			tree.Drain()
+			insertAll(tree)
+		}))
+	})
+
+	t.Run("nil_rand", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithReservoir[int](3, nil))
+		for v := 0; v < 100; v++ {
+			tree.Insert(v)
+		}
+		if tree.Size() != 3 {
+			t.Errorf("Size() = %d, want 3", tree.Size())
+		}
+	})
+
+	t.Run("nil_rand_not_shared", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithReservoir[int](3, nil))
+		clone := tree.Clone()
+
+		// Run under -race: the trees must not share a generator
+		var wg sync.WaitGroup
+		for _, tr := range []*Tree[int]{tree, clone} {
+			wg.Add(1)
+			go func(tr *Tree[int]) {
+				defer wg.Done()
+				for v := 0; v < 1000; v++ {
+					tr.Insert(v)
+				}
+			}(tr)
+		}
+		wg.Wait()
+
+		if tree.reservoir == clone.reservoir {
+			t.Error("Clone shares the generator created for a nil rand")
+		}
+		for _, tr := range []*Tree[int]{tree, clone} {
+			if tr.Size() != 3 {
+				t.Errorf("Size() = %d, want 3", tr.Size())
+			}
+		}
+	})
+
+	t.Run("last_option_wins", func(t *testing.T) {
+		t.Parallel()
+
+		checkUniform(t, inclusion(t, insertAll, WithMaxSize[int](3, EvictSmallest)))
+
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithReservoir[int](3, rand.New(rand.NewSource(4))), WithMaxSize[int](3, EvictSmallest))
+		insertAll(tree)
+		if got, want := tree.SortedSnapshot(), []int{7, 8, 9}; !slices.Equal(got, want) {
+			t.Errorf("WithMaxSize after WithReservoir kept %v, want %v", got, want)
+		}
+	})
+}
+
+func TestWithComparatorChecks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("consistent_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithComparatorChecks[int]())
+		for _, v := range []int{5, 3, 8, 3, 1, 9} {
+			tree.Insert(v)
+		}
+		tree.Delete(3)
+
+		if size := tree.Size(); size != 5 {
+			t.Errorf("Size() = %d, want 5", size)
+		}
+		checkRedBlackProperties(t, tree)
+	})
+
+	t.Run("broken_comparator_panics", func(t *testing.T) {
+		t.Parallel()
+
+		// Claims every element is greater than every other one
+		broken := func(_, _ int) int { return 1 }
+		tree := NewTree[int](broken, WithComparatorChecks[int]())
+		tree.Insert(1)
+
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal("Insert with broken comparator should panic")
+			}
+			msg, ok := r.(string)
+			if !ok || !strings.Contains(msg, "inconsistent comparator") {
+				t.Errorf("panic = %v, want message about inconsistent comparator", r)
+			}
+		}()
+		tree.Insert(2)
+	})
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(_, _ int) int { return 1 })
+		tree.Insert(1)
+		tree.Insert(2) // must not panic
+
+		if size := tree.Size(); size != 2 {
+			t.Errorf("Size() = %d, want 2", size)
+		}
+	})
+}
+
+func TestWithRotationHook(t *testing.T) {
+	t.Parallel()
+
+	type rotation struct {
+		kind  RotationKind
+		pivot int
+	}
+
+	t.Run("increasing_inserts", func(t *testing.T) {
+		t.Parallel()
+
+		var rotations []rotation
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithRotationHook(func(kind RotationKind, pivot int) {
+				rotations = append(rotations, rotation{kind: kind, pivot: pivot})
+			}))
+
+		// Inserting 3, 5 and 7 rotates their grandparents left; 4 and 6 only recolor
+		for i := 1; i <= 7; i++ {
+			tree.Insert(i)
+		}
+
+		want := []rotation{{RotateLeft, 1}, {RotateLeft, 3}, {RotateLeft, 5}}
+		if !slices.Equal(rotations, want) {
+			t.Errorf("rotations = %v, want %v", rotations, want)
+		}
+	})
+
+	t.Run("rotation_directions", func(t *testing.T) {
+		t.Parallel()
+
+		left, right := 0, 0
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithRotationHook(func(kind RotationKind, _ int) {
+				switch kind {
+				case RotateLeft:
+					left++
+				case RotateRight:
+					right++
+				}
+			}))
+
+		for i := 100; i > 0; i-- {
+			tree.Insert(i)
+		}
+		if left != 0 || right == 0 {
+			t.Errorf("decreasing inserts rotated left %d and right %d times, want only right rotations", left, right)
+		}
+		for i := 1; i <= 100; i++ {
+			tree.Delete(i)
+		}
+		if left == 0 {
+			t.Error("deleting from the left should trigger left rotations")
+		}
+	})
+}
+
+func TestWithSortedAppend(t *testing.T) {
+	t.Parallel()
+
+	newTree := func(opts ...Option[int]) *Tree[int] {
+		return NewTree[int](func(a, b int) int { return a - b }, append(opts, WithSortedAppend[int]())...)
+	}
+
+	t.Run("ordered_load_compares_once", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree(WithComparatorCounter[int]())
+		var want []int
+		for i := 0; i < 500; i++ {
+			tree.Insert(i / 2)
+			want = append(want, i/2)
+		}
+
+		if got := tree.ComparatorCalls(); got != 499 {
+			t.Errorf("ComparatorCalls() = %d, want 499", got)
+		}
+		if got := tree.SortedSnapshot(); !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("out_of_order_falls_back", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree()
+		values := []int{10, 20, 5, 30, 15, 30, 1, 40}
+		for _, v := range values {
+			tree.Insert(v)
+		}
+
+		want := slices.Clone(values)
+		slices.Sort(want)
+		if got := tree.SortedSnapshot(); !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("cache_survives_mutations", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree()
+		for i := 0; i < 100; i++ {
+			tree.Insert(i)
+		}
+
+		tree.Delete(99) // removes the cached maximum
+		tree.Insert(99)
+		tree.DeleteRankRange(90, 100)
+		tree.Insert(95)
+		if err := tree.InsertAscending(96); err != nil {
+			t.Fatalf("InsertAscending(96) error = %v", err)
+		}
+		tree.Insert(97)
+
+		clone := tree.Clone()
+		clone.Insert(200)
+		tree.Insert(98)
+
+		if got, want := tree.SortedSnapshot()[88:], []int{88, 89, 95, 96, 97, 98}; !slices.Equal(got, want) {
+			t.Errorf("tail = %v, want %v", got, want)
+		}
+		if got, want := clone.SortedSnapshot()[88:], []int{88, 89, 95, 96, 97, 200}; !slices.Equal(got, want) {
+			t.Errorf("clone tail = %v, want %v", got, want)
+		}
+
+		tree.Drain()
+		tree.Insert(1)
+		tree.Insert(2)
+		if got, want := tree.SortedSnapshot(), []int{1, 2}; !slices.Equal(got, want) {
+			t.Errorf("after Drain = %v, want %v", got, want)
+		}
+
+		for _, tr := range []*Tree[int]{tree, clone} {
+			checkRedBlackProperties(t, tr)
+			verifySizes(t, tr.root, tr.nil)
+		}
+	})
+}
+
+func TestWithEquality(t *testing.T) {
+	t.Parallel()
+
+	type player struct {
+		score int
+		name  string
+	}
+	byScore := func(a, b player) int { return a.score - b.score }
+	sameName := func(a, b player) bool { return a.name == b.name }
+
+	newTree := func(opts ...Option[player]) *Tree[player] {
+		tree := NewTree(byScore, opts...)
+		for _, p := range []player{{10, "ann"}, {20, "bob"}, {20, "cid"}, {20, "dan"}, {30, "eve"}} {
+			tree.Insert(p)
+		}
+
+		return tree
+	}
+
+	t.Run("search_distinguishes_ties", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree(WithEquality(sameName))
+		for _, p := range []player{{20, "bob"}, {20, "cid"}, {20, "dan"}, {10, "ann"}} {
+			if !tree.Search(p) {
+				t.Errorf("Search(%v) = false, want true", p)
+			}
+		}
+		for _, p := range []player{{20, "zed"}, {10, "bob"}, {25, "cid"}} {
+			if tree.Search(p) {
+				t.Errorf("Search(%v) = true, want false", p)
+			}
+		}
+	})
+
+	t.Run("delete_removes_exact_match", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree(WithEquality(sameName))
+		if tree.Delete(player{20, "zed"}) {
+			t.Error("Delete({20 zed}) = true, want false")
+		}
+		if !tree.Delete(player{20, "cid"}) {
+			t.Fatal("Delete({20 cid}) = false, want true")
+		}
+		if tree.Search(player{20, "cid"}) {
+			t.Error("{20 cid} still present after Delete")
+		}
+
+		var names []string
+		for _, p := range tree.SortedSnapshot() {
+			names = append(names, p.name)
+		}
+		if want := []string{"ann", "bob", "dan", "eve"}; !slices.Equal(names, want) {
+			t.Errorf("remaining = %v, want %v", names, want)
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("delete_many_with_ranks", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree(WithEquality(sameName))
+		ranks := tree.DeleteManyWithRanks([]player{{20, "dan"}, {20, "zed"}, {20, "bob"}})
+		if want := []int{3, -1, 1}; !slices.Equal(ranks, want) {
+			t.Errorf("DeleteManyWithRanks() = %v, want %v", ranks, want)
+		}
+
+		var names []string
+		for _, p := range tree.SortedSnapshot() {
+			names = append(names, p.name)
+		}
+		if want := []string{"ann", "cid", "eve"}; !slices.Equal(names, want) {
+			t.Errorf("remaining = %v, want %v", names, want)
+		}
+		checkRedBlackProperties(t, tree)
+	})
+
+	t.Run("default_uses_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := newTree()
+		if !tree.Search(player{20, "zed"}) {
+			t.Error("Search({20 zed}) = false without WithEquality, want true")
+		}
+		if !tree.Delete(player{20, "zed"}) || tree.Size() != 4 {
+			t.Errorf("Delete({20 zed}) should remove one tied element, Size() = %d", tree.Size())
+		}
+	})
+}
+
+func TestWithFixupHook(t *testing.T) {
+	t.Parallel()
+
+	type step struct {
+		phase      FixupPhase
+		caseNumber int
+		key        int
+	}
+
+	t.Run("insert_cases", func(t *testing.T) {
+		t.Parallel()
+
+		var steps []step
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithFixupHook(func(phase FixupPhase, caseNumber, key int) {
+				steps = append(steps, step{phase: phase, caseNumber: caseNumber, key: key})
+			}))
+
+		// 30 is an outer grandchild (case 3), 15 has a red uncle (case 1) and
+		// 12 is an inner grandchild (case 2), which turns into case 3 for its parent 15
+		for _, key := range []int{10, 20, 30, 15, 12} {
+			tree.Insert(key)
+		}
+
+		want := []step{{FixupInsert, 3, 30}, {FixupInsert, 1, 15}, {FixupInsert, 2, 12}, {FixupInsert, 3, 15}}
+		if !slices.Equal(steps, want) {
+			t.Errorf("steps = %v, want %v", steps, want)
+		}
+		checkRedBlackProperties(t, tree)
+	})
+
+	t.Run("delete_cases", func(t *testing.T) {
+		t.Parallel()
+
+		var seen [2][5]int
+		tree := NewTree[int](func(a, b int) int { return a - b },
+			WithFixupHook(func(phase FixupPhase, caseNumber, _ int) {
+				seen[phase][caseNumber]++
+			}))
+		for i := 0; i < 200; i++ {
+			tree.Insert(i * 37 % 200)
+		}
+		for i := 0; i < 200; i++ {
+			tree.Delete(i * 53 % 200)
+		}
+
+		if seen[FixupDelete][0] != 0 {
+			t.Errorf("delete case 0 reported %d times", seen[FixupDelete][0])
+		}
+		for caseNumber := 1; caseNumber <= 4; caseNumber++ {
+			if seen[FixupDelete][caseNumber] == 0 {
+				t.Errorf("delete case %d was never reported", caseNumber)
+			}
+		}
+	})
+}
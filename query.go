@@ -0,0 +1,505 @@
+package gostree
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// Span returns the half-open rank range [start, end) occupied by all copies of key,
+// so end-start is the number of occurrences. When the key is absent, start == end
+// and both equal the rank at which the key would be found.
+func (t *Tree[T]) Span(key T) (start, end int) {
+	return t.Rank(key), t.rankUpper(key)
+}
+
+// CountBetween returns the number of keys k with lo < k < hi, excluding both
+// bounds and all of their duplicates, in O(log n). It returns 0 when lo >= hi.
+func (t *Tree[T]) CountBetween(lo, hi T) int {
+	if t.compare(lo, hi) >= 0 {
+		return 0
+	}
+
+	return t.Rank(hi) - t.rankUpper(lo)
+}
+
+// AppendRange appends all keys k with lo <= k <= hi to dst in ascending order
+// and returns the extended slice. It allocates only when dst needs to grow,
+// so callers can reuse a buffer across calls. An inverted range returns dst unchanged.
+func (t *Tree[T]) AppendRange(dst []T, lo, hi T) []T {
+	if t.compare(lo, hi) > 0 {
+		return dst
+	}
+
+	for node := t.lowerBound(lo); node != t.nil && t.compare(node.key, hi) <= 0; node = t.successor(node) {
+		dst = append(dst, node.key)
+	}
+
+	return dst
+}
+
+// Fill writes consecutive elements in ascending order, starting at rank fromRank,
+// into dst until dst is full or the elements run out, and returns the number written.
+// It seeks in O(log n) and never allocates, which suits windowed rendering.
+// A fromRank outside [0, Size()) writes nothing.
+func (t *Tree[T]) Fill(dst []T, fromRank int) int {
+	if fromRank < 0 || fromRank >= t.root.size {
+		return 0
+	}
+
+	n := 0
+	for node := t.selectNode(t.root, fromRank); node != t.nil && n < len(dst); node = t.successor(node) {
+		dst[n] = node.key
+		n++
+	}
+
+	return n
+}
+
+// Floor returns the largest key less than or equal to the given key.
+// It is the inclusive counterpart of Predecessor.
+func (t *Tree[T]) Floor(key T) (T, bool) {
+	return t.keyOf(t.floorNode(key))
+}
+
+// Ceiling returns the smallest key greater than or equal to the given key.
+// It is the inclusive counterpart of Successor.
+func (t *Tree[T]) Ceiling(key T) (T, bool) {
+	return t.keyOf(t.lowerBound(key))
+}
+
+// SelectClamped returns the element at rank k clamped into [0, Size()-1], so a
+// negative k yields the smallest element and a k past the end yields the largest.
+// Unlike Select, which rejects out-of-range ranks, it returns false only when
+// the tree is empty.
+func (t *Tree[T]) SelectClamped(k int) (T, bool) {
+	if t.root == t.nil {
+		var zero T
+
+		return zero, false
+	}
+
+	return t.selectNode(t.root, min(max(k, 0), t.root.size-1)).key, true
+}
+
+// CeilingMany returns Ceiling for each of the probes, in the order of probes.
+// The i-th found value reports whether probes[i] has a ceiling; if not, the
+// i-th key is the zero value.
+//
+// When a probe is not less than the one before it, the walk steps through
+// successors from the previous ceiling and falls back to a fresh O(log n)
+// descent only if the next ceiling is further than the tree height away.
+// Sorted probes therefore cost O(m + n) at most instead of O(m log n),
+// and unsorted probes cost the same as independent Ceiling calls.
+func (t *Tree[T]) CeilingMany(probes []T) (keys []T, found []bool) {
+	keys = make([]T, len(probes))
+	found = make([]bool, len(probes))
+	node := t.nil
+	for i, probe := range probes {
+		descend := func() *Node[T] { return t.lowerBound(probe) }
+		if i > 0 && t.compare(probe, probes[i-1]) >= 0 {
+			// Every key before the previous ceiling is less than probe
+			node = t.seek(node, func(n *Node[T]) bool { return t.compare(n.key, probe) < 0 }, descend)
+		} else {
+			node = descend()
+		}
+		keys[i], found[i] = t.keyOf(node)
+	}
+
+	return keys, found
+}
+
+// maxSuccessorSteps returns how many successor steps are worth taking before
+// a fresh descent from the root is cheaper: stepping further than the tree
+// height costs more than the descent.
+func (t *Tree[T]) maxSuccessorSteps() int {
+	return 2 * bits.Len(uint(t.root.size))
+}
+
+// seek steps from node through successors while behind reports that the target
+// lies further on, and returns the first node for which it does not. When the
+// distance to the target is not known in advance, it gives up after
+// maxSuccessorSteps steps and returns the result of descend instead. A sentinel
+// node is returned as is.
+func (t *Tree[T]) seek(node *Node[T], behind func(*Node[T]) bool, descend func() *Node[T]) *Node[T] {
+	maxStep := t.maxSuccessorSteps()
+	for step := 0; node != t.nil && behind(node); step++ {
+		if step == maxStep {
+			return descend()
+		}
+		node = t.successor(node)
+	}
+
+	return node
+}
+
+// SelectMany returns the elements at the given ranks, in the order of ranks,
+// or nil and false if any rank is outside [0, Size()).
+//
+// Each rank costs at most one O(log n) descent, as with independent Select calls.
+// When consecutive ranks are ascending and close together, the walk instead steps
+// through successors from the previous element, so dense sorted rank lists cost
+// O(log n + span) in total, where span is the distance between the first and last rank.
+func (t *Tree[T]) SelectMany(ranks []int) ([]T, bool) {
+	size := t.root.size
+	for _, rank := range ranks {
+		if rank < 0 || rank >= size {
+			return nil, false
+		}
+	}
+
+	maxStep := t.maxSuccessorSteps()
+	keys := make([]T, 0, len(ranks))
+	node, prev := t.nil, 0
+	for _, rank := range ranks {
+		if node != t.nil && rank >= prev && rank-prev <= maxStep {
+			for ; prev < rank; prev++ {
+				node = t.successor(node)
+			}
+		} else {
+			node, prev = t.selectNode(t.root, rank), rank
+		}
+		keys = append(keys, node.key)
+	}
+
+	return keys, true
+}
+
+// Position returns the rank of the leftmost occurrence of key together with
+// the total number of elements and whether the key is present, as needed to
+// render a scroll position. It takes a single descent. When the key is absent,
+// rank is the position at which it would be inserted.
+func (t *Tree[T]) Position(key T) (rank, total int, found bool) {
+	for current := t.root; current != t.nil; {
+		cmp := t.compare(key, current.key)
+		if cmp <= 0 {
+			found = found || cmp == 0
+			current = current.left
+		} else {
+			rank += current.left.size + 1
+			current = current.right
+		}
+	}
+
+	return rank, t.root.size, found
+}
+
+// SumRanks returns the sum of Rank(key) over keys, taking one descent per key.
+// Keys may be given in any order and may repeat.
+func (t *Tree[T]) SumRanks(keys []T) int {
+	sum := 0
+	for _, key := range keys {
+		sum += t.Rank(key)
+	}
+
+	return sum
+}
+
+// FloorCeiling returns both Floor and Ceiling of key in a single descent,
+// halving the comparisons of two separate calls. When the key is present,
+// floor and ceil are both equal to it.
+func (t *Tree[T]) FloorCeiling(key T) (floor T, floorOK bool, ceil T, ceilOK bool) {
+	floorNode, ceilNode := t.nil, t.nil
+	for current := t.root; current != t.nil; {
+		cmp := t.compare(key, current.key)
+		if cmp == 0 {
+			floorNode, ceilNode = current, current
+
+			break
+		} else if cmp < 0 {
+			ceilNode = current
+			current = current.left
+		} else {
+			floorNode = current
+			current = current.right
+		}
+	}
+
+	floor, floorOK = t.keyOf(floorNode)
+	ceil, ceilOK = t.keyOf(ceilNode)
+
+	return floor, floorOK, ceil, ceilOK
+}
+
+// Locate snaps key to a stored element. Since keys have no notion of distance,
+// nearest is defined by order: the key itself if present, otherwise its floor
+// (the largest smaller key), or its ceiling when key is below the minimum.
+// cmp is the sign of compare(key, nearest): 0 for an exact match, 1 when key lies
+// above nearest and -1 when it lies below. found is false only for an empty tree.
+func (t *Tree[T]) Locate(key T) (nearest T, cmp int, found bool) {
+	floor, floorOK, ceil, ceilOK := t.FloorCeiling(key)
+	switch {
+	case floorOK:
+		return floor, sign(t.compare(key, floor)), true
+	case ceilOK:
+		return ceil, -1, true
+	default:
+		return nearest, 0, false
+	}
+}
+
+// NearestN returns up to n elements closest to key according to dist, in ascending
+// order. dist must return a non-negative distance that grows as keys move away from
+// key in either direction. Starting from the floor of key, the search expands
+// outward and takes the closer neighbor at each step; on ties the smaller key wins.
+// It runs in O(log n + n) and returns nil for n <= 0.
+func (t *Tree[T]) NearestN(key T, n int, dist func(a, b T) int) []T {
+	if n <= 0 {
+		return nil
+	}
+
+	lo := t.floorNode(key)
+	hi := t.first()
+	if lo != t.nil {
+		hi = t.successor(lo)
+	}
+
+	var below, above []T
+	for len(below)+len(above) < n && (lo != t.nil || hi != t.nil) {
+		if hi == t.nil || lo != t.nil && dist(key, lo.key) <= dist(key, hi.key) {
+			below = append(below, lo.key)
+			lo = t.predecessor(lo)
+		} else {
+			above = append(above, hi.key)
+			hi = t.successor(hi)
+		}
+	}
+
+	slices.Reverse(below)
+
+	return append(below, above...)
+}
+
+// Predecessor returns the largest key strictly less than the given key.
+// Use Floor to also accept a key equal to the given one.
+func (t *Tree[T]) Predecessor(key T) (T, bool) {
+	return t.keyOf(t.lowerNode(key))
+}
+
+// Successor returns the smallest key strictly greater than the given key.
+// Use Ceiling to also accept a key equal to the given one.
+func (t *Tree[T]) Successor(key T) (T, bool) {
+	return t.keyOf(t.upperBound(key))
+}
+
+// keyOf returns the key of the node, or the zero value and false for the sentinel
+func (t *Tree[T]) keyOf(node *Node[T]) (T, bool) {
+	if node == t.nil {
+		var zero T
+
+		return zero, false
+	}
+
+	return node.key, true
+}
+
+// RangeMedian returns the median of the keys k with lo <= k <= hi.
+// For an even number of keys the lower median is returned.
+// It returns false if the range is empty or inverted.
+func (t *Tree[T]) RangeMedian(lo, hi T) (T, bool) {
+	if t.compare(lo, hi) > 0 {
+		var zero T
+
+		return zero, false
+	}
+
+	start := t.Rank(lo)
+	count := t.rankUpper(hi) - start
+	if count == 0 {
+		var zero T
+
+		return zero, false
+	}
+
+	return t.Select(start + (count-1)/2)
+}
+
+// Quantiles returns the k-1 keys that split the tree into k buckets of roughly
+// equal size: the keys at ranks Size()*i/k for i in 1..k-1. It runs in O(k log n)
+// and returns an empty slice for k <= 1 or an empty tree.
+func (t *Tree[T]) Quantiles(k int) []T {
+	size := t.root.size
+	if k <= 1 || size == 0 {
+		return nil
+	}
+
+	// k comes from the caller, so do not let it size the allocation up front
+	quantiles := make([]T, 0, min(k-1, size+1))
+	for i := 1; i < k; i++ {
+		quantiles = append(quantiles, t.selectNode(t.root, size*i/k).key)
+	}
+
+	return quantiles
+}
+
+// AllMatch reports whether every element satisfies pred, visiting elements in
+// ascending order and stopping at the first one that does not.
+// It returns true for an empty tree.
+func (t *Tree[T]) AllMatch(pred func(T) bool) bool {
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		if !pred(node.key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AnyMatch reports whether at least one element satisfies pred, visiting elements
+// in ascending order and stopping at the first one that does.
+// It returns false for an empty tree.
+func (t *Tree[T]) AnyMatch(pred func(T) bool) bool {
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		if pred(node.key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SmallestMatching returns, in ascending order, up to n smallest elements for
+// which pred returns true. The walk stops as soon as n matches are found, so only
+// the prefix of the tree up to the n-th match is visited. It returns nil for n <= 0.
+func (t *Tree[T]) SmallestMatching(n int, pred func(T) bool) []T {
+	if n <= 0 {
+		return nil
+	}
+
+	var matches []T
+	for node := t.first(); node != t.nil && len(matches) < n; node = t.successor(node) {
+		if pred(node.key) {
+			matches = append(matches, node.key)
+		}
+	}
+
+	return matches
+}
+
+// Mode returns the most frequent key and its number of occurrences, or false for
+// an empty tree. When several keys share the highest count, the smallest one is
+// returned. It is computed in a single in-order walk in O(n).
+func (t *Tree[T]) Mode() (T, int, bool) {
+	var best T
+	bestCount := 0
+	t.GroupEqual()(func(key T, count int) bool {
+		if count > bestCount {
+			best, bestCount = key, count
+		}
+
+		return true
+	})
+
+	return best, bestCount, bestCount > 0
+}
+
+// FilteredSelect returns the k-th smallest element (0-indexed) among those
+// satisfying pred, or false if fewer than k+1 elements match. Because a predicate
+// cannot use the subtree sizes, it walks in order and runs in O(n).
+func (t *Tree[T]) FilteredSelect(pred func(T) bool, k int) (T, bool) {
+	if k >= 0 {
+		for node := t.first(); node != t.nil; node = t.successor(node) {
+			if !pred(node.key) {
+				continue
+			}
+			if k == 0 {
+				return node.key, true
+			}
+			k--
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// InsertionRank returns the rank key would occupy if it were inserted now,
+// without modifying the tree. Insert places a key after existing equal keys,
+// so this is the number of elements less than or equal to key.
+func (t *Tree[T]) InsertionRank(key T) int {
+	return t.rankUpper(key)
+}
+
+// InsertionRanks returns InsertionRank for each key of batch, in the order of batch.
+// Each rank is relative to the tree as it is now and ignores the other batch keys;
+// since Insert places a key after existing equal keys, a key equal to elements of
+// the tree lands after all of them. If the whole sorted batch were inserted, the
+// i-th key would end up at rank InsertionRanks(batch)[i] + i.
+//
+// For a batch sorted in ascending order the ranks are computed in a single in-order
+// walk in O(n + m). A key smaller than its predecessor in batch is not an error;
+// it costs two O(log n) descents instead.
+func (t *Tree[T]) InsertionRanks(sortedBatch []T) []int {
+	ranks := make([]int, len(sortedBatch))
+	node, rank := t.first(), 0
+	for i, key := range sortedBatch {
+		if i > 0 && t.compare(key, sortedBatch[i-1]) < 0 {
+			node, rank = t.upperBound(key), t.rankUpper(key)
+		}
+		for node != t.nil && t.compare(node.key, key) <= 0 {
+			node = t.successor(node)
+			rank++
+		}
+		ranks[i] = rank
+	}
+
+	return ranks
+}
+
+// ClosestAdjacentPair returns the two consecutive elements in ascending order
+// with the smallest distance between them, as measured by dist, together with
+// that distance. Among pairs at the same distance the smallest one is returned;
+// equal keys are adjacent and usually have distance 0. It walks the tree once
+// in O(n) and returns false if the tree has fewer than two elements.
+func (t *Tree[T]) ClosestAdjacentPair(dist func(a, b T) int) (a, b T, d int, ok bool) {
+	node := t.first()
+	if node == t.nil {
+		return a, b, 0, false
+	}
+
+	for next := t.successor(node); next != t.nil; node, next = next, t.successor(next) {
+		if gap := dist(node.key, next.key); !ok || gap < d {
+			a, b, d, ok = node.key, next.key, gap, true
+		}
+	}
+
+	return a, b, d, ok
+}
+
+// SelectWithSubtree is like Select but also returns the half-open rank range
+// [subtreeStart, subtreeEnd) covered by the subtree rooted at the selected node,
+// so subtreeEnd-subtreeStart is the size of that subtree. It is intended for
+// layout and rendering of the tree shape.
+func (t *Tree[T]) SelectWithSubtree(k int) (key T, subtreeStart, subtreeEnd int, ok bool) {
+	if k < 0 || k >= t.root.size {
+		return key, 0, 0, false
+	}
+
+	// offset is the rank of the leftmost element of the current subtree
+	offset := 0
+	current := t.root
+	for {
+		leftSize := current.left.size
+		switch {
+		case k < offset+leftSize:
+			current = current.left
+		case k == offset+leftSize:
+			return current.key, offset, offset + current.size, true
+		default:
+			offset += leftSize + 1
+			current = current.right
+		}
+	}
+}
+
+// ContainsRange reports whether any key k satisfies lo <= k <= hi.
+// It checks the ceiling of lo against hi in O(log n) without counting or
+// collecting elements. An inverted range returns false.
+func (t *Tree[T]) ContainsRange(lo, hi T) bool {
+	if t.compare(lo, hi) > 0 {
+		return false
+	}
+	node := t.lowerBound(lo)
+
+	return node != t.nil && t.compare(node.key, hi) <= 0
+}
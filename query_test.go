@@ -0,0 +1,1239 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if start, end := tree.Span(10); start != 0 || end != 0 {
+			t.Errorf("Span(10) = %d, %d; want 0, 0", start, end)
+		}
+	})
+
+	t.Run("with_duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{5, 3, 7, 3, 5, 7, 5, 1})
+
+		// Sorted: 1, 3, 3, 5, 5, 5, 7, 7
+		testCases := []struct {
+			key           int
+			expectedStart int
+			expectedEnd   int
+		}{
+			{1, 0, 1},
+			{3, 1, 3},
+			{5, 3, 6},
+			{7, 6, 8},
+		}
+
+		for _, tc := range testCases {
+			start, end := tree.Span(tc.key)
+			if start != tc.expectedStart || end != tc.expectedEnd {
+				t.Errorf("Span(%d) = %d, %d; want %d, %d", tc.key, start, end, tc.expectedStart, tc.expectedEnd)
+			}
+			for k := start; k < end; k++ {
+				if got, _ := tree.Select(k); got != tc.key {
+					t.Errorf("Select(%d) = %d, want %d", k, got, tc.key)
+				}
+			}
+		}
+	})
+
+	t.Run("absent_keys", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 20, 30})
+
+		testCases := []struct {
+			key      int
+			expected int
+		}{
+			{5, 0},
+			{15, 1},
+			{25, 3},
+			{35, 4},
+		}
+
+		for _, tc := range testCases {
+			start, end := tree.Span(tc.key)
+			if start != tc.expected || end != tc.expected {
+				t.Errorf("Span(%d) = %d, %d; want %d, %d", tc.key, start, end, tc.expected, tc.expected)
+			}
+		}
+	})
+}
+
+func TestCountBetween(t *testing.T) {
+	t.Parallel()
+
+	tree := buildTree([]int{10, 20, 20, 30, 30, 30, 40})
+
+	testCases := []struct {
+		name   string
+		lo, hi int
+		want   int
+	}{
+		{name: "bounds_on_keys", lo: 10, hi: 40, want: 5},
+		{name: "bounds_on_duplicates", lo: 20, hi: 30, want: 0},
+		{name: "lo_on_duplicates", lo: 20, hi: 35, want: 3},
+		{name: "between_keys", lo: 15, hi: 35, want: 5},
+		{name: "covers_all", lo: 0, hi: 100, want: 7},
+		{name: "equal_bounds", lo: 30, hi: 30, want: 0},
+		{name: "inverted", lo: 40, hi: 10, want: 0},
+		{name: "outside", lo: 41, hi: 50, want: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tree.CountBetween(tc.lo, tc.hi); got != tc.want {
+				t.Errorf("CountBetween(%d, %d) = %d, want %d", tc.lo, tc.hi, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAppendRange(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if got := tree.AppendRange(nil, 0, 100); len(got) != 0 {
+			t.Errorf("AppendRange() = %v, want empty", got)
+		}
+	})
+
+	t.Run("inclusive_bounds", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 20, 30, 40, 50})
+
+		testCases := []struct {
+			lo, hi   int
+			expected []int
+		}{
+			{20, 40, []int{20, 20, 30, 40}},
+			{15, 35, []int{20, 20, 30}},
+			{0, 100, []int{10, 20, 20, 30, 40, 50}},
+			{50, 50, []int{50}},
+			{21, 29, []int{}},
+			{60, 70, []int{}},
+		}
+
+		for _, tc := range testCases {
+			got := tree.AppendRange(nil, tc.lo, tc.hi)
+			if len(got) != len(tc.expected) {
+				t.Errorf("AppendRange(%d, %d) = %v, want %v", tc.lo, tc.hi, got, tc.expected)
+
+				continue
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Errorf("AppendRange(%d, %d) = %v, want %v", tc.lo, tc.hi, got, tc.expected)
+
+					break
+				}
+			}
+		}
+	})
+
+	t.Run("inverted_range_returns_dst", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		dst := []int{42}
+
+		got := tree.AppendRange(dst, 3, 1)
+		if len(got) != 1 || got[0] != 42 {
+			t.Errorf("AppendRange(3, 1) = %v, want [42]", got)
+		}
+	})
+
+	t.Run("appends_to_existing_contents", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4})
+
+		got := tree.AppendRange([]int{0}, 2, 3)
+		if len(got) != 3 || got[0] != 0 || got[1] != 2 || got[2] != 3 {
+			t.Errorf("AppendRange(2, 3) = %v, want [0 2 3]", got)
+		}
+	})
+}
+
+//nolint:paralleltest // testing.AllocsPerRun cannot be used in parallel tests
+func TestAppendRangeAllocations(t *testing.T) {
+	tree := buildTree([]int{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	buf := make([]int, 0, 16)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = tree.AppendRange(buf[:0], 3, 7)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendRange allocated %v times per run, want 0", allocs)
+	}
+}
+
+func TestFill(t *testing.T) {
+	t.Parallel()
+
+	tree := buildTree([]int{50, 10, 40, 20, 30})
+
+	testCases := []struct {
+		name     string
+		dstLen   int
+		fromRank int
+		want     []int
+	}{
+		{name: "whole_tree", dstLen: 5, fromRank: 0, want: []int{10, 20, 30, 40, 50}},
+		{name: "window_fits", dstLen: 2, fromRank: 1, want: []int{20, 30}},
+		{name: "partial_fill", dstLen: 4, fromRank: 3, want: []int{40, 50}},
+		{name: "empty_dst", dstLen: 0, fromRank: 0, want: []int{}},
+		{name: "start_past_end", dstLen: 3, fromRank: 5, want: []int{}},
+		{name: "negative_start", dstLen: 3, fromRank: -1, want: []int{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dst := make([]int, tc.dstLen)
+			n := tree.Fill(dst, tc.fromRank)
+			if n != len(tc.want) || !slices.Equal(dst[:n], tc.want) {
+				t.Errorf("Fill(len %d, %d) = %d, %v, want %d, %v", tc.dstLen, tc.fromRank, n, dst[:n], len(tc.want), tc.want)
+			}
+			for _, v := range dst[n:] {
+				if v != 0 {
+					t.Errorf("Fill() wrote past the returned count: %v", dst)
+				}
+			}
+		})
+	}
+}
+
+func TestFillAllocations(t *testing.T) { //nolint:paralleltest // testing.AllocsPerRun cannot be used in parallel tests
+	tree := buildTree([]int{5, 3, 8, 1, 4, 7, 9})
+	dst := make([]int, 4)
+
+	if allocs := testing.AllocsPerRun(100, func() { tree.Fill(dst, 2) }); allocs != 0 {
+		t.Errorf("Fill() allocated %v times, want 0", allocs)
+	}
+}
+
+func TestFloorCeilingPredecessorSuccessor(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		key int
+		ok  bool
+	}
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		for name, fn := range map[string]func(int) (int, bool){
+			"Floor":       tree.Floor,
+			"Ceiling":     tree.Ceiling,
+			"Predecessor": tree.Predecessor,
+			"Successor":   tree.Successor,
+		} {
+			if _, ok := fn(10); ok {
+				t.Errorf("%s(10) should return false for empty tree", name)
+			}
+		}
+	})
+
+	t.Run("lookups", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 20, 30, 40})
+
+		testCases := []struct {
+			key         int
+			floor       result
+			ceiling     result
+			predecessor result
+			successor   result
+		}{
+			{5, result{0, false}, result{10, true}, result{0, false}, result{10, true}},
+			{10, result{10, true}, result{10, true}, result{0, false}, result{20, true}},
+			{15, result{10, true}, result{20, true}, result{10, true}, result{20, true}},
+			{20, result{20, true}, result{20, true}, result{10, true}, result{30, true}},
+			{40, result{40, true}, result{40, true}, result{30, true}, result{0, false}},
+			{45, result{40, true}, result{0, false}, result{40, true}, result{0, false}},
+		}
+
+		for _, tc := range testCases {
+			if key, ok := tree.Floor(tc.key); (result{key, ok}) != tc.floor {
+				t.Errorf("Floor(%d) = %d, %v; want %+v", tc.key, key, ok, tc.floor)
+			}
+			if key, ok := tree.Ceiling(tc.key); (result{key, ok}) != tc.ceiling {
+				t.Errorf("Ceiling(%d) = %d, %v; want %+v", tc.key, key, ok, tc.ceiling)
+			}
+			if key, ok := tree.Predecessor(tc.key); (result{key, ok}) != tc.predecessor {
+				t.Errorf("Predecessor(%d) = %d, %v; want %+v", tc.key, key, ok, tc.predecessor)
+			}
+			if key, ok := tree.Successor(tc.key); (result{key, ok}) != tc.successor {
+				t.Errorf("Successor(%d) = %d, %v; want %+v", tc.key, key, ok, tc.successor)
+			}
+		}
+	})
+
+	t.Run("present_keys", func(t *testing.T) {
+		t.Parallel()
+
+		values := []int{3, 8, 15, 16, 23, 42}
+		tree := buildTree(values)
+
+		for i, v := range values {
+			floor, _ := tree.Floor(v)
+			ceiling, _ := tree.Ceiling(v)
+			if floor != v || ceiling != v {
+				t.Errorf("Floor(%d), Ceiling(%d) = %d, %d; want %d, %d", v, v, floor, ceiling, v, v)
+			}
+
+			predecessor, ok := tree.Predecessor(v)
+			if i == 0 && ok {
+				t.Errorf("Predecessor(%d) should return false for minimum", v)
+			} else if i > 0 && (!ok || predecessor != values[i-1]) {
+				t.Errorf("Predecessor(%d) = %d, %v; want %d, true", v, predecessor, ok, values[i-1])
+			}
+
+			successor, ok := tree.Successor(v)
+			if i == len(values)-1 && ok {
+				t.Errorf("Successor(%d) should return false for maximum", v)
+			} else if i < len(values)-1 && (!ok || successor != values[i+1]) {
+				t.Errorf("Successor(%d) = %d, %v; want %d, true", v, successor, ok, values[i+1])
+			}
+		}
+	})
+}
+
+func TestSelectClamped(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		if got, ok := buildTree(nil).SelectClamped(0); ok || got != 0 {
+			t.Errorf("SelectClamped(0) on empty tree = %d, %v, want 0, false", got, ok)
+		}
+	})
+
+	tree := buildTree([]int{40, 10, 30, 20, 50})
+
+	testCases := []struct {
+		name string
+		k    int
+		want int
+	}{
+		{name: "negative", k: -3, want: 10},
+		{name: "zero", k: 0, want: 10},
+		{name: "in_range", k: 2, want: 30},
+		{name: "last", k: 4, want: 50},
+		{name: "oversized", k: 99, want: 50},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, ok := tree.SelectClamped(tc.k); !ok || got != tc.want {
+				t.Errorf("SelectClamped(%d) = %d, %v, want %d, true", tc.k, got, ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestCeilingMany(t *testing.T) {
+	t.Parallel()
+
+	values := make([]int, 0, 100)
+	for i := 0; i < 100; i++ {
+		values = append(values, i*10, i*10)
+	}
+	tree := buildTree(values)
+
+	testCases := []struct {
+		name   string
+		probes []int
+	}{
+		{name: "empty", probes: nil},
+		{name: "sorted_dense", probes: []int{-5, 0, 1, 10, 11, 15, 20, 20, 29}},
+		{name: "sorted_sparse", probes: []int{3, 250, 251, 700, 989, 990}},
+		{name: "unsorted", probes: []int{500, 5, 990, 0, 345, 340}},
+		{name: "past_maximum", probes: []int{985, 991, 2000, 995, 10}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, found := tree.CeilingMany(tc.probes)
+			if len(keys) != len(tc.probes) || len(found) != len(tc.probes) {
+				t.Fatalf("CeilingMany() returned %d keys and %d flags, want %d", len(keys), len(found), len(tc.probes))
+			}
+			for i, probe := range tc.probes {
+				want, wantOK := tree.Ceiling(probe)
+				if keys[i] != want || found[i] != wantOK {
+					t.Errorf("probe %d: got %d, %v, want %d, %v", probe, keys[i], found[i], want, wantOK)
+				}
+			}
+		})
+	}
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		keys, found := buildTree(nil).CeilingMany([]int{1, 2})
+		if !slices.Equal(keys, []int{0, 0}) || !slices.Equal(found, []bool{false, false}) {
+			t.Errorf("CeilingMany() on empty tree = %v, %v, want zero values and false", keys, found)
+		}
+	})
+}
+
+func TestSelectMany(t *testing.T) {
+	t.Parallel()
+
+	values := make([]int, 200)
+	for i := range values {
+		values[i] = i * 10
+	}
+	tree := buildTree(values)
+
+	testCases := []struct {
+		name  string
+		ranks []int
+	}{
+		{name: "empty", ranks: []int{}},
+		{name: "sorted_sparse", ranks: []int{0, 10, 50, 199}},
+		{name: "sorted_dense", ranks: []int{3, 4, 5, 7, 8, 12, 13}},
+		{name: "unsorted", ranks: []int{99, 0, 150, 2, 1}},
+		{name: "repeated", ranks: []int{5, 5, 6, 5}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tree.SelectMany(tc.ranks)
+			if !ok {
+				t.Fatalf("SelectMany(%v) ok = false, want true", tc.ranks)
+			}
+
+			want := make([]int, 0, len(tc.ranks))
+			for _, rank := range tc.ranks {
+				want = append(want, values[rank])
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("SelectMany(%v) = %v, want %v", tc.ranks, got, want)
+			}
+		})
+	}
+
+	t.Run("out_of_range", func(t *testing.T) {
+		t.Parallel()
+
+		for _, ranks := range [][]int{{0, 200}, {-1}, {5, 300, 6}} {
+			if got, ok := tree.SelectMany(ranks); ok || got != nil {
+				t.Errorf("SelectMany(%v) = %v, %v, want nil, false", ranks, got, ok)
+			}
+		}
+	})
+
+	t.Run("with_duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		dups := buildTree([]int{4, 1, 4, 4, 2, 9})
+		got, ok := dups.SelectMany([]int{1, 2, 3, 4, 5})
+		if want := []int{2, 4, 4, 4, 9}; !ok || !slices.Equal(got, want) {
+			t.Errorf("SelectMany() = %v, %v, want %v, true", got, ok, want)
+		}
+	})
+}
+
+func TestPosition(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if rank, total, found := tree.Position(5); rank != 0 || total != 0 || found {
+			t.Errorf("Position(5) = %d, %d, %v, want 0, 0, false", rank, total, found)
+		}
+	})
+
+	tree := buildTree([]int{10, 20, 20, 30, 40})
+
+	testCases := []struct {
+		name      string
+		key       int
+		wantRank  int
+		wantFound bool
+	}{
+		{name: "min", key: 10, wantRank: 0, wantFound: true},
+		{name: "max", key: 40, wantRank: 4, wantFound: true},
+		{name: "duplicate_leftmost", key: 20, wantRank: 1, wantFound: true},
+		{name: "absent_middle", key: 25, wantRank: 3, wantFound: false},
+		{name: "absent_below_min", key: 5, wantRank: 0, wantFound: false},
+		{name: "absent_above_max", key: 50, wantRank: 5, wantFound: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rank, total, found := tree.Position(tc.key)
+			if rank != tc.wantRank || total != 5 || found != tc.wantFound {
+				t.Errorf("Position(%d) = %d, %d, %v, want %d, 5, %v", tc.key, rank, total, found, tc.wantRank, tc.wantFound)
+			}
+			if rank != tree.Rank(tc.key) || found != tree.Search(tc.key) {
+				t.Errorf("Position(%d) disagrees with Rank and Search", tc.key)
+			}
+		})
+	}
+}
+
+func TestSumRanks(t *testing.T) {
+	t.Parallel()
+
+	tree := buildTree([]int{10, 20, 20, 30, 40})
+
+	testCases := []struct {
+		name string
+		keys []int
+	}{
+		{name: "none", keys: nil},
+		{name: "present", keys: []int{40, 10, 30}},
+		{name: "duplicates_and_repeats", keys: []int{20, 20, 20}},
+		{name: "absent", keys: []int{5, 25, 50}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			want := 0
+			for _, key := range tc.keys {
+				want += tree.Rank(key)
+			}
+			if got := tree.SumRanks(tc.keys); got != want {
+				t.Errorf("SumRanks(%v) = %d, want %d", tc.keys, got, want)
+			}
+		})
+	}
+
+	if got := tree.SumRanks([]int{40, 10, 30, 25}); got != 4+0+3+3 {
+		t.Errorf("SumRanks() = %d, want 10", got)
+	}
+}
+
+func TestFloorCeiling(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, floorOK, _, ceilOK := tree.FloorCeiling(10); floorOK || ceilOK {
+			t.Error("FloorCeiling(10) should return false for empty tree")
+		}
+	})
+
+	t.Run("matches_separate_calls", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 20, 30, 40, 55, 70})
+		for key := 0; key <= 80; key++ {
+			floor, floorOK, ceil, ceilOK := tree.FloorCeiling(key)
+			wantFloor, wantFloorOK := tree.Floor(key)
+			wantCeil, wantCeilOK := tree.Ceiling(key)
+			if floor != wantFloor || floorOK != wantFloorOK || ceil != wantCeil || ceilOK != wantCeilOK {
+				t.Errorf("FloorCeiling(%d) = %d, %v, %d, %v; want %d, %v, %d, %v",
+					key, floor, floorOK, ceil, ceilOK, wantFloor, wantFloorOK, wantCeil, wantCeilOK)
+			}
+		}
+	})
+
+	t.Run("present_key", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 5, 9})
+		if floor, floorOK, ceil, ceilOK := tree.FloorCeiling(5); floor != 5 || ceil != 5 || !floorOK || !ceilOK {
+			t.Errorf("FloorCeiling(5) = %d, %v, %d, %v; want 5, true, 5, true", floor, floorOK, ceil, ceilOK)
+		}
+	})
+
+	t.Run("single_descent", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b }, WithComparatorCounter[int]())
+		for i := 0; i < 1000; i += 2 {
+			tree.Insert(i)
+		}
+
+		for _, key := range []int{-1, 333, 1001} {
+			before := tree.ComparatorCalls()
+			tree.FloorCeiling(key)
+			combined := tree.ComparatorCalls() - before
+
+			before = tree.ComparatorCalls()
+			tree.Floor(key)
+			tree.Ceiling(key)
+			separate := tree.ComparatorCalls() - before
+
+			if combined*2 > separate {
+				t.Errorf("FloorCeiling(%d) made %d comparisons, separate calls %d", key, combined, separate)
+			}
+		}
+	})
+}
+
+func TestLocate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, _, found := tree.Locate(5); found {
+			t.Error("Locate(5) found = true on empty tree, want false")
+		}
+	})
+
+	tree := buildTree([]int{10, 20, 20, 30})
+
+	testCases := []struct {
+		name        string
+		key         int
+		wantNearest int
+		wantCmp     int
+	}{
+		{name: "exact_match", key: 20, wantNearest: 20, wantCmp: 0},
+		{name: "between_prefers_floor", key: 25, wantNearest: 20, wantCmp: 1},
+		{name: "just_below_key", key: 29, wantNearest: 20, wantCmp: 1},
+		{name: "below_minimum", key: 5, wantNearest: 10, wantCmp: -1},
+		{name: "above_maximum", key: 99, wantNearest: 30, wantCmp: 1},
+		{name: "minimum", key: 10, wantNearest: 10, wantCmp: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nearest, cmp, found := tree.Locate(tc.key)
+			if nearest != tc.wantNearest || cmp != tc.wantCmp || !found {
+				t.Errorf("Locate(%d) = %d, %d, %v, want %d, %d, true",
+					tc.key, nearest, cmp, found, tc.wantNearest, tc.wantCmp)
+			}
+		})
+	}
+}
+
+func TestNearestN(t *testing.T) {
+	t.Parallel()
+
+	dist := func(a, b int) int {
+		if a > b {
+			return a - b
+		}
+
+		return b - a
+	}
+	tree := buildTree([]int{1, 4, 6, 10, 10, 15, 30})
+
+	testCases := []struct {
+		name string
+		key  int
+		n    int
+		want []int
+	}{
+		{name: "zero", key: 10, n: 0, want: nil},
+		{name: "exact_match", key: 10, n: 2, want: []int{10, 10}},
+		{name: "around_present_key", key: 6, n: 3, want: []int{4, 6, 10}},
+		{name: "between_keys", key: 12, n: 3, want: []int{10, 10, 15}},
+		// 5 is 1 away from both 4 and 6; the smaller key wins the tie
+		{name: "tie_prefers_smaller", key: 5, n: 1, want: []int{4}},
+		{name: "tie_both_taken", key: 5, n: 2, want: []int{4, 6}},
+		{name: "below_minimum", key: -5, n: 3, want: []int{1, 4, 6}},
+		{name: "above_maximum", key: 100, n: 2, want: []int{15, 30}},
+		{name: "near_upper_boundary", key: 29, n: 3, want: []int{10, 15, 30}},
+		{name: "more_than_size", key: 7, n: 50, want: []int{1, 4, 6, 10, 10, 15, 30}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tree.NearestN(tc.key, tc.n, dist); !slices.Equal(got, tc.want) {
+				t.Errorf("NearestN(%d, %d) = %v, want %v", tc.key, tc.n, got, tc.want)
+			}
+		})
+	}
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		empty := NewTree[int](func(a, b int) int { return a - b })
+		if got := empty.NearestN(5, 3, dist); len(got) != 0 {
+			t.Errorf("NearestN() = %v on empty tree, want empty", got)
+		}
+	})
+}
+
+func TestRangeMedian(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, ok := tree.RangeMedian(0, 100); ok {
+			t.Error("RangeMedian should return false for empty tree")
+		}
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+		testCases := []struct {
+			lo, hi   int
+			expected int
+		}{
+			{1, 10, 5},  // 10 elements, lower median
+			{1, 9, 5},   // 9 elements
+			{3, 5, 4},   // 3 elements
+			{4, 4, 4},   // single element
+			{0, 2, 1},   // window starting before minimum
+			{8, 100, 9}, // window ending after maximum
+			{2, 5, 3},   // even window
+		}
+
+		for _, tc := range testCases {
+			got, ok := tree.RangeMedian(tc.lo, tc.hi)
+			if !ok || got != tc.expected {
+				t.Errorf("RangeMedian(%d, %d) = %d, %v; want %d, true", tc.lo, tc.hi, got, ok, tc.expected)
+			}
+		}
+	})
+
+	t.Run("with_duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 5, 5, 5, 9, 20})
+
+		got, ok := tree.RangeMedian(1, 9)
+		if !ok || got != 5 {
+			t.Errorf("RangeMedian(1, 9) = %d, %v; want 5, true", got, ok)
+		}
+	})
+
+	t.Run("empty_and_inverted_ranges", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30})
+
+		if _, ok := tree.RangeMedian(11, 19); ok {
+			t.Error("RangeMedian(11, 19) should return false for range without keys")
+		}
+		if _, ok := tree.RangeMedian(30, 10); ok {
+			t.Error("RangeMedian(30, 10) should return false for inverted range")
+		}
+	})
+}
+
+func TestQuantiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("quartiles_of_100_elements", func(t *testing.T) {
+		t.Parallel()
+
+		values := make([]int, 100)
+		for i := range values {
+			values[i] = (i * 37) % 100 // 0..99 in scrambled order
+		}
+		tree := buildTree(values)
+
+		got := tree.Quantiles(4)
+		if !slices.Equal(got, []int{25, 50, 75}) {
+			t.Errorf("Quantiles(4) = %v, want [25 50 75]", got)
+		}
+	})
+
+	t.Run("more_buckets_than_elements", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30})
+
+		got := tree.Quantiles(6)
+		if !slices.Equal(got, []int{10, 20, 20, 30, 30}) {
+			t.Errorf("Quantiles(6) = %v, want [10 20 20 30 30]", got)
+		}
+	})
+
+	t.Run("degenerate_inputs", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		for _, k := range []int{-1, 0, 1} {
+			if got := tree.Quantiles(k); len(got) != 0 {
+				t.Errorf("Quantiles(%d) = %v, want empty", k, got)
+			}
+		}
+
+		if got := buildTree(nil).Quantiles(4); len(got) != 0 {
+			t.Errorf("Quantiles(4) on empty tree = %v, want empty", got)
+		}
+	})
+
+	t.Run("very_large_k", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30})
+
+		const k = 1 << 20
+		got := tree.Quantiles(k)
+		if len(got) != k-1 {
+			t.Fatalf("len(Quantiles(%d)) = %d, want %d", k, len(got), k-1)
+		}
+		if !slices.IsSorted(got) || got[0] != 10 || got[len(got)-1] != 30 {
+			t.Errorf("Quantiles(%d) = [%d ... %d], want sorted from 10 to 30", k, got[0], got[len(got)-1])
+		}
+	})
+}
+
+func TestAllMatchAnyMatch(t *testing.T) {
+	t.Parallel()
+
+	isEven := func(v int) bool { return v%2 == 0 }
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree(nil)
+		if !tree.AllMatch(isEven) {
+			t.Error("AllMatch should return true for empty tree")
+		}
+		if tree.AnyMatch(isEven) {
+			t.Error("AnyMatch should return false for empty tree")
+		}
+	})
+
+	t.Run("results", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name     string
+			values   []int
+			expected [2]bool // AllMatch, AnyMatch
+		}{
+			{"all_even", []int{2, 4, 6}, [2]bool{true, true}},
+			{"some_even", []int{1, 2, 3}, [2]bool{false, true}},
+			{"none_even", []int{1, 3, 5}, [2]bool{false, false}},
+		}
+
+		for _, tc := range testCases {
+			tree := buildTree(tc.values)
+			if got := tree.AllMatch(isEven); got != tc.expected[0] {
+				t.Errorf("%s: AllMatch() = %v, want %v", tc.name, got, tc.expected[0])
+			}
+			if got := tree.AnyMatch(isEven); got != tc.expected[1] {
+				t.Errorf("%s: AnyMatch() = %v, want %v", tc.name, got, tc.expected[1])
+			}
+		}
+	})
+
+	t.Run("short_circuit", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5, 6})
+
+		var visited []int
+		tree.AllMatch(func(v int) bool {
+			visited = append(visited, v)
+
+			return v < 3
+		})
+		if !slices.Equal(visited, []int{1, 2, 3}) {
+			t.Errorf("AllMatch visited %v, want [1 2 3]", visited)
+		}
+
+		visited = nil
+		tree.AnyMatch(func(v int) bool {
+			visited = append(visited, v)
+
+			return v == 2
+		})
+		if !slices.Equal(visited, []int{1, 2}) {
+			t.Errorf("AnyMatch visited %v, want [1 2]", visited)
+		}
+	})
+}
+
+func TestSmallestMatching(t *testing.T) {
+	t.Parallel()
+
+	even := func(v int) bool { return v%2 == 0 }
+	tree := buildTree([]int{9, 2, 7, 4, 4, 1, 8, 3, 6, 10})
+
+	testCases := []struct {
+		name string
+		n    int
+		want []int
+	}{
+		{name: "zero", n: 0, want: nil},
+		{name: "three_smallest_even", n: 3, want: []int{2, 4, 4}},
+		{name: "all_matches", n: 6, want: []int{2, 4, 4, 6, 8, 10}},
+		{name: "fewer_than_n", n: 10, want: []int{2, 4, 4, 6, 8, 10}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tree.SmallestMatching(tc.n, even); !slices.Equal(got, tc.want) {
+				t.Errorf("SmallestMatching(%d, even) = %v, want %v", tc.n, got, tc.want)
+			}
+		})
+	}
+
+	t.Run("stops_early", func(t *testing.T) {
+		t.Parallel()
+
+		var visited []int
+		got := tree.SmallestMatching(2, func(v int) bool {
+			visited = append(visited, v)
+
+			return even(v)
+		})
+		if want := []int{2, 4}; !slices.Equal(got, want) {
+			t.Errorf("SmallestMatching(2, even) = %v, want %v", got, want)
+		}
+		if want := []int{1, 2, 3, 4}; !slices.Equal(visited, want) {
+			t.Errorf("predicate called on %v, want %v", visited, want)
+		}
+	})
+}
+
+func TestMode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		values    []int
+		wantKey   int
+		wantCount int
+		wantOK    bool
+	}{
+		{name: "empty", values: nil, wantKey: 0, wantCount: 0, wantOK: false},
+		{name: "single", values: []int{7}, wantKey: 7, wantCount: 1, wantOK: true},
+		{name: "clear_winner", values: []int{1, 3, 3, 3, 2, 2, 9}, wantKey: 3, wantCount: 3, wantOK: true},
+		{name: "tie_prefers_smallest", values: []int{8, 8, 4, 4, 6, 6, 1}, wantKey: 4, wantCount: 2, wantOK: true},
+		{name: "uniform_counts", values: []int{5, 3, 9, 1}, wantKey: 1, wantCount: 1, wantOK: true},
+		{name: "winner_last", values: []int{1, 2, 9, 9, 9}, wantKey: 9, wantCount: 3, wantOK: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, count, ok := buildTree(tc.values).Mode()
+			if key != tc.wantKey || count != tc.wantCount || ok != tc.wantOK {
+				t.Errorf("Mode() = %d, %d, %v, want %d, %d, %v", key, count, ok, tc.wantKey, tc.wantCount, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestFilteredSelect(t *testing.T) {
+	t.Parallel()
+
+	even := func(v int) bool { return v%2 == 0 }
+	tree := buildTree([]int{7, 2, 9, 4, 4, 1, 8, 3, 6})
+
+	testCases := []struct {
+		name   string
+		k      int
+		want   int
+		wantOK bool
+	}{
+		{name: "first_even", k: 0, want: 2, wantOK: true},
+		{name: "duplicate_even", k: 2, want: 4, wantOK: true},
+		{name: "median_even", k: 3, want: 6, wantOK: true},
+		{name: "last_even", k: 4, want: 8, wantOK: true},
+		{name: "too_few_matches", k: 5, want: 0, wantOK: false},
+		{name: "negative_k", k: -1, want: 0, wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, ok := tree.FilteredSelect(even, tc.k); got != tc.want || ok != tc.wantOK {
+				t.Errorf("FilteredSelect(even, %d) = %d, %v, want %d, %v", tc.k, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+
+	t.Run("no_matches", func(t *testing.T) {
+		t.Parallel()
+
+		if _, ok := tree.FilteredSelect(func(v int) bool { return v > 100 }, 0); ok {
+			t.Error("FilteredSelect() ok = true with no matches, want false")
+		}
+	})
+}
+
+func TestInsertionRank(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		if rank := buildTree(nil).InsertionRank(5); rank != 0 {
+			t.Errorf("InsertionRank(5) = %d, want 0", rank)
+		}
+	})
+
+	t.Run("matches_actual_insert", func(t *testing.T) {
+		t.Parallel()
+
+		values := []int{10, 20, 20, 30, 40}
+		testCases := []struct {
+			key      int
+			expected int
+		}{
+			{5, 0},  // absent, before minimum
+			{10, 1}, // present, after the existing copy
+			{20, 3}, // present with duplicates, after all copies
+			{25, 3}, // absent, between keys
+			{40, 5}, // present maximum
+			{45, 5}, // absent, after maximum
+		}
+
+		for _, tc := range testCases {
+			tree := buildTree(values)
+
+			rank := tree.InsertionRank(tc.key)
+			if rank != tc.expected {
+				t.Errorf("InsertionRank(%d) = %d, want %d", tc.key, rank, tc.expected)
+			}
+			if size := tree.Size(); size != len(values) {
+				t.Errorf("InsertionRank(%d) modified tree size to %d", tc.key, size)
+			}
+
+			// The new node is placed after existing equal keys
+			tree.Insert(tc.key)
+			if _, end := tree.Span(tc.key); end-1 != rank {
+				t.Errorf("after Insert(%d) the last copy is at rank %d, want %d", tc.key, end-1, rank)
+			}
+		}
+	})
+}
+
+func TestInsertionRanks(t *testing.T) {
+	t.Parallel()
+
+	tree := buildTree([]int{10, 20, 20, 30, 40})
+
+	testCases := []struct {
+		name  string
+		batch []int
+		want  []int
+	}{
+		{name: "empty_batch", batch: nil, want: []int{}},
+		{name: "straddling", batch: []int{5, 10, 15, 20, 20, 25, 40, 45}, want: []int{0, 1, 1, 3, 3, 3, 5, 5}},
+		{name: "all_below", batch: []int{1, 2, 3}, want: []int{0, 0, 0}},
+		{name: "all_above", batch: []int{50, 60}, want: []int{5, 5}},
+		{name: "unsorted", batch: []int{35, 10, 45, 20, 0}, want: []int{4, 1, 5, 3, 0}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tree.InsertionRanks(tc.batch)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("InsertionRanks(%v) = %v, want %v", tc.batch, got, tc.want)
+			}
+			for i, key := range tc.batch {
+				if got[i] != tree.InsertionRank(key) {
+					t.Errorf("rank of %d = %d, InsertionRank = %d", key, got[i], tree.InsertionRank(key))
+				}
+			}
+		})
+	}
+
+	t.Run("final_positions", func(t *testing.T) {
+		t.Parallel()
+
+		batch := []int{15, 20, 35}
+		merged := buildTree([]int{10, 20, 20, 30, 40})
+		ranks := merged.InsertionRanks(batch)
+		for _, key := range batch {
+			merged.Insert(key)
+		}
+		for i, key := range batch {
+			if got, _ := merged.Select(ranks[i] + i); got != key {
+				t.Errorf("Select(%d) after merge = %d, want %d", ranks[i]+i, got, key)
+			}
+		}
+	})
+}
+
+func TestClosestAdjacentPair(t *testing.T) {
+	t.Parallel()
+
+	dist := func(a, b int) int { return b - a }
+
+	testCases := []struct {
+		name   string
+		values []int
+		wantA  int
+		wantB  int
+		wantD  int
+		wantOK bool
+	}{
+		{name: "empty", values: nil, wantA: 0, wantB: 0, wantD: 0, wantOK: false},
+		{name: "single", values: []int{5}, wantA: 0, wantB: 0, wantD: 0, wantOK: false},
+		{name: "two", values: []int{9, 2}, wantA: 2, wantB: 9, wantD: 7, wantOK: true},
+		{name: "unique_minimum", values: []int{1, 10, 14, 30, 31, 50}, wantA: 30, wantB: 31, wantD: 1, wantOK: true},
+		{name: "ties_pick_smallest", values: []int{40, 3, 20, 23, 0, 43}, wantA: 0, wantB: 3, wantD: 3, wantOK: true},
+		{name: "duplicates", values: []int{1, 8, 15, 8, 22}, wantA: 8, wantB: 8, wantD: 0, wantOK: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b, d, ok := buildTree(tc.values).ClosestAdjacentPair(dist)
+			if a != tc.wantA || b != tc.wantB || d != tc.wantD || ok != tc.wantOK {
+				t.Errorf("ClosestAdjacentPair() = %d, %d, %d, %v, want %d, %d, %d, %v",
+					a, b, d, ok, tc.wantA, tc.wantB, tc.wantD, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestSelectWithSubtree(t *testing.T) {
+	t.Parallel()
+
+	t.Run("out_of_range", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		for _, k := range []int{-1, 3} {
+			if _, _, _, ok := tree.SelectWithSubtree(k); ok {
+				t.Errorf("SelectWithSubtree(%d) ok = true, want false", k)
+			}
+		}
+	})
+
+	t.Run("known_shape", func(t *testing.T) {
+		t.Parallel()
+
+		// 20(B) with children 10(B) and 30(B), 40(R) under 30
+		tree := buildTree([]int{10, 20, 30, 40})
+
+		type result struct {
+			key, start, end int
+		}
+		want := []result{{10, 0, 1}, {20, 0, 4}, {30, 2, 4}, {40, 3, 4}}
+		for k, w := range want {
+			key, start, end, ok := tree.SelectWithSubtree(k)
+			if got := (result{key, start, end}); !ok || got != w {
+				t.Errorf("SelectWithSubtree(%d) = %+v, %v, want %+v, true", k, got, ok, w)
+			}
+		}
+	})
+
+	t.Run("range_matches_subtree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{50, 20, 80, 10, 30, 70, 90, 25, 35, 65, 5, 5, 30})
+		for k := 0; k < tree.Size(); k++ {
+			key, start, end, ok := tree.SelectWithSubtree(k)
+			node := tree.selectNode(tree.root, k)
+			if !ok || key != node.key {
+				t.Fatalf("SelectWithSubtree(%d) = %d, %v, want %d, true", k, key, ok, node.key)
+			}
+			if end-start != node.size {
+				t.Errorf("k=%d: subtreeEnd-subtreeStart = %d, want %d", k, end-start, node.size)
+			}
+			if wantStart := k - node.left.size; start != wantStart {
+				t.Errorf("k=%d: subtreeStart = %d, want %d", k, start, wantStart)
+			}
+		}
+	})
+}
+
+func TestContainsRange(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if tree.ContainsRange(0, 100) {
+			t.Error("ContainsRange(0, 100) = true on empty tree, want false")
+		}
+	})
+
+	tree := buildTree([]int{10, 20, 30, 40})
+
+	testCases := []struct {
+		name   string
+		lo, hi int
+		want   bool
+	}{
+		{name: "covers_all", lo: 0, hi: 100, want: true},
+		{name: "gap_between_keys", lo: 21, hi: 29, want: false},
+		{name: "touches_lower_bound", lo: 30, hi: 35, want: true},
+		{name: "touches_upper_bound", lo: 25, hi: 30, want: true},
+		{name: "single_point_hit", lo: 40, hi: 40, want: true},
+		{name: "single_point_miss", lo: 41, hi: 41, want: false},
+		{name: "below_minimum", lo: 0, hi: 9, want: false},
+		{name: "above_maximum", lo: 41, hi: 100, want: false},
+		{name: "inverted", lo: 30, hi: 10, want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tree.ContainsRange(tc.lo, tc.hi); got != tc.want {
+				t.Errorf("ContainsRange(%d, %d) = %v, want %v", tc.lo, tc.hi, got, tc.want)
+			}
+		})
+	}
+}
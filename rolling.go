@@ -0,0 +1,62 @@
+package gostree
+
+import "cmp"
+
+// RollingStats collects numeric or otherwise ordered samples and answers
+// percentile queries over all samples added so far in O(log n) each.
+// It is a thin facade over a Tree ordered by cmp.Compare.
+type RollingStats[T cmp.Ordered] struct {
+	tree *Tree[T]
+}
+
+// NewRollingStats creates an empty RollingStats.
+func NewRollingStats[T cmp.Ordered]() *RollingStats[T] {
+	return &RollingStats[T]{
+		tree: NewTree(cmp.Compare[T]),
+	}
+}
+
+// Add records a sample.
+func (s *RollingStats[T]) Add(x T) {
+	s.tree.Insert(x)
+}
+
+// Count returns the number of samples recorded.
+func (s *RollingStats[T]) Count() int {
+	return s.tree.Size()
+}
+
+// Min returns the smallest sample, or false if there are none.
+func (s *RollingStats[T]) Min() (T, bool) {
+	return s.tree.Select(0)
+}
+
+// Max returns the largest sample, or false if there are none.
+func (s *RollingStats[T]) Max() (T, bool) {
+	return s.tree.Select(s.tree.Size() - 1)
+}
+
+// P50 returns the median sample, or false if there are none.
+func (s *RollingStats[T]) P50() (T, bool) {
+	return s.Percentile(50)
+}
+
+// P90 returns the 90th percentile sample, or false if there are none.
+func (s *RollingStats[T]) P90() (T, bool) {
+	return s.Percentile(90)
+}
+
+// P99 returns the 99th percentile sample, or false if there are none.
+func (s *RollingStats[T]) P99() (T, bool) {
+	return s.Percentile(99)
+}
+
+// Percentile returns the sample at rank Count()*p/100, matching the split points
+// of Quantiles(100), so p = 0 is the minimum and p = 100 is the maximum.
+// p is clamped to [0, 100]. It returns false if there are no samples.
+func (s *RollingStats[T]) Percentile(p int) (T, bool) {
+	size := s.tree.Size()
+	p = min(max(p, 0), 100)
+
+	return s.tree.Select(min(size*p/100, size-1))
+}
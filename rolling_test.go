@@ -0,0 +1,105 @@
+package gostree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRollingStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		stats := NewRollingStats[float64]()
+		for name, fn := range map[string]func() (float64, bool){
+			"Min": stats.Min,
+			"Max": stats.Max,
+			"P50": stats.P50,
+			"P90": stats.P90,
+			"P99": stats.P99,
+		} {
+			if _, ok := fn(); ok {
+				t.Errorf("%s() ok = true with no samples, want false", name)
+			}
+		}
+		if stats.Count() != 0 {
+			t.Errorf("Count() = %d, want 0", stats.Count())
+		}
+	})
+
+	t.Run("single_sample", func(t *testing.T) {
+		t.Parallel()
+
+		stats := NewRollingStats[int]()
+		stats.Add(42)
+		for _, p := range []int{0, 50, 99, 100} {
+			if got, ok := stats.Percentile(p); !ok || got != 42 {
+				t.Errorf("Percentile(%d) = %d, %v, want 42, true", p, got, ok)
+			}
+		}
+	})
+
+	t.Run("known_distribution", func(t *testing.T) {
+		t.Parallel()
+
+		// Samples 1..1000 in random order
+		stats := NewRollingStats[int]()
+		for _, i := range rand.New(rand.NewSource(1)).Perm(1000) {
+			stats.Add(i + 1)
+		}
+
+		testCases := []struct {
+			name string
+			fn   func() (int, bool)
+			want int
+		}{
+			{name: "Min", fn: stats.Min, want: 1},
+			{name: "Max", fn: stats.Max, want: 1000},
+			{name: "P50", fn: stats.P50, want: 501},
+			{name: "P90", fn: stats.P90, want: 901},
+			{name: "P99", fn: stats.P99, want: 991},
+		}
+		for _, tc := range testCases {
+			if got, ok := tc.fn(); !ok || got != tc.want {
+				t.Errorf("%s() = %d, %v, want %d, true", tc.name, got, ok, tc.want)
+			}
+		}
+		if stats.Count() != 1000 {
+			t.Errorf("Count() = %d, want 1000", stats.Count())
+		}
+	})
+
+	t.Run("continuous_ingestion", func(t *testing.T) {
+		t.Parallel()
+
+		// Uniform samples in [0, 1): percentiles converge to p/100
+		stats := NewRollingStats[float64]()
+		r := rand.New(rand.NewSource(7))
+		for i := 0; i < 20000; i++ {
+			stats.Add(r.Float64())
+		}
+
+		for p, want := range map[int]float64{50: 0.5, 90: 0.9, 99: 0.99} {
+			got, _ := stats.Percentile(p)
+			if got < want-0.02 || got > want+0.02 {
+				t.Errorf("Percentile(%d) = %.3f, want %.2f ± 0.02", p, got, want)
+			}
+		}
+	})
+
+	t.Run("clamps_percentile", func(t *testing.T) {
+		t.Parallel()
+
+		stats := NewRollingStats[int]()
+		for _, v := range []int{3, 1, 2} {
+			stats.Add(v)
+		}
+		if got, _ := stats.Percentile(-10); got != 1 {
+			t.Errorf("Percentile(-10) = %d, want 1", got)
+		}
+		if got, _ := stats.Percentile(200); got != 3 {
+			t.Errorf("Percentile(200) = %d, want 3", got)
+		}
+	})
+}
@@ -0,0 +1,315 @@
+package gostree
+
+import "container/heap"
+
+// AddFrom merges all elements of other into the tree, summing multiplicities
+// of equal keys (multiset union). The other tree is left intact.
+//
+// Both trees are walked in order and the receiver is rebuilt from the merged
+// sequence, which takes O(n+m) time instead of O(m log(n+m)) for repeated inserts.
+// Existing copies of a key are placed before copies coming from other.
+func (t *Tree[T]) AddFrom(other *Tree[T]) {
+	if other.root == other.nil {
+		return
+	}
+
+	a := t.appendInOrder(make([]T, 0, t.root.size))
+	b := other.appendInOrder(make([]T, 0, other.root.size))
+	merged := mergeSorted(make([]T, 0, len(a)+len(b)), a, b, t.compare)
+
+	t.buildSorted(merged)
+}
+
+// IntersectionCount returns the number of elements common to both trees
+// with multiset semantics: a key present x times in a and y times in b
+// contributes min(x, y). It walks both trees in order in O(n+m) time
+// and O(1) extra space, using the comparator of a.
+func IntersectionCount[T any](a, b *Tree[T]) int {
+	count := 0
+	nodeA, nodeB := a.first(), b.first()
+	for nodeA != a.nil && nodeB != b.nil {
+		cmp := a.compare(nodeA.key, nodeB.key)
+		switch {
+		case cmp < 0:
+			nodeA = a.successor(nodeA)
+		case cmp > 0:
+			nodeB = b.successor(nodeB)
+		default:
+			count++
+			nodeA = a.successor(nodeA)
+			nodeB = b.successor(nodeB)
+		}
+	}
+
+	return count
+}
+
+// Origin tells which tree a key yielded by Zip came from.
+type Origin int
+
+const (
+	// InA marks a key present only in the first tree.
+	InA Origin = iota
+	// InB marks a key present only in the second tree.
+	InB
+	// InBoth marks a key present in both trees.
+	InBoth
+)
+
+// Zip returns an iterator over the keys of both trees in ascending order,
+// each tagged with the tree it came from. Equal keys are paired up one-to-one,
+// so with duplicates a key present x times in a and y times in b is yielded
+// min(x, y) times as InBoth and the remaining copies as InA or InB.
+//
+// Both trees must use compatible comparators; the comparator of a is used.
+// The iterator follows the iter.Seq2 convention and stops when yield returns false.
+// Neither tree may be modified during iteration.
+func Zip[T any](a, b *Tree[T]) func(yield func(T, Origin) bool) {
+	return func(yield func(T, Origin) bool) {
+		nodeA, nodeB := a.first(), b.first()
+		for nodeA != a.nil && nodeB != b.nil {
+			cmp := a.compare(nodeA.key, nodeB.key)
+			switch {
+			case cmp < 0:
+				if !yield(nodeA.key, InA) {
+					return
+				}
+				nodeA = a.successor(nodeA)
+			case cmp > 0:
+				if !yield(nodeB.key, InB) {
+					return
+				}
+				nodeB = b.successor(nodeB)
+			default:
+				if !yield(nodeA.key, InBoth) {
+					return
+				}
+				nodeA = a.successor(nodeA)
+				nodeB = b.successor(nodeB)
+			}
+		}
+		for ; nodeA != a.nil; nodeA = a.successor(nodeA) {
+			if !yield(nodeA.key, InA) {
+				return
+			}
+		}
+		for ; nodeB != b.nil; nodeB = b.successor(nodeB) {
+			if !yield(nodeB.key, InB) {
+				return
+			}
+		}
+	}
+}
+
+// RetainAll removes from the tree every element not present in other and returns
+// the number of elements removed. With multiset semantics, a key is retained at most
+// as many times as it occurs in other. The other tree is left intact.
+//
+// The receiver is rebuilt from a merged in-order walk in O(n+m) time.
+func (t *Tree[T]) RetainAll(other *Tree[T]) int {
+	kept := make([]T, 0, min(t.root.size, other.root.size))
+	node, otherNode := t.first(), other.first()
+	for node != t.nil && otherNode != other.nil {
+		cmp := t.compare(node.key, otherNode.key)
+		switch {
+		case cmp < 0:
+			node = t.successor(node)
+		case cmp > 0:
+			otherNode = other.successor(otherNode)
+		default:
+			kept = append(kept, node.key)
+			node = t.successor(node)
+			otherNode = other.successor(otherNode)
+		}
+	}
+
+	removed := t.root.size - len(kept)
+	if removed > 0 {
+		t.buildSorted(kept)
+	}
+
+	return removed
+}
+
+// UnionAll returns a new tree holding all elements of the given trees
+// (multiset union). The trees are left intact.
+//
+// The in-order sequences are combined with a k-way merge over a heap of cursors
+// and the result is bulk-built in O(N log k) time, where N is the total number
+// of elements. Equal keys keep the order of the trees they come from.
+// The result shares the comparator and options of the first tree; with a single
+// tree it is a clone, and with no trees it is an empty tree without a comparator.
+func UnionAll[T any](trees ...*Tree[T]) *Tree[T] {
+	switch len(trees) {
+	case 0:
+		return NewTree[T](nil)
+	case 1:
+		return trees[0].Clone()
+	}
+
+	total := 0
+	cursors := &unionHeap[T]{items: make([]unionCursor[T], 0, len(trees)), compare: trees[0].compare}
+	for i, tree := range trees {
+		total += tree.root.size
+		if node := tree.first(); node != tree.nil {
+			cursors.items = append(cursors.items, unionCursor[T]{node: node, tree: tree, index: i})
+		}
+	}
+	heap.Init(cursors)
+
+	merged := make([]T, 0, total)
+	for cursors.Len() > 0 {
+		top := &cursors.items[0]
+		merged = append(merged, top.node.key)
+		top.node = top.tree.successor(top.node)
+		if top.node == top.tree.nil {
+			heap.Pop(cursors)
+		} else {
+			heap.Fix(cursors, 0)
+		}
+	}
+
+	result := trees[0].emptyCopy()
+	result.buildSorted(merged)
+
+	return result
+}
+
+// UnionStable returns a new tree holding all elements of a and b (multiset union),
+// in which every copy of a key coming from a precedes every copy coming from b,
+// and copies from the same tree keep their order. Select and in-order iteration
+// over duplicates are therefore predictable, which matters when elements carry
+// payloads that the comparator ignores. The trees are left intact.
+//
+// Both in-order sequences are merged and bulk-built in O(n+m) time. The result
+// shares the comparator and options of a. AddFrom and UnionAll order equal keys
+// the same way.
+func UnionStable[T any](a, b *Tree[T]) *Tree[T] {
+	keysA := a.appendInOrder(make([]T, 0, a.root.size))
+	keysB := b.appendInOrder(make([]T, 0, b.root.size))
+
+	result := a.emptyCopy()
+	result.buildSorted(mergeSorted(make([]T, 0, len(keysA)+len(keysB)), keysA, keysB, a.compare))
+
+	return result
+}
+
+// unionCursor is the position of UnionAll in one of the merged trees.
+type unionCursor[T any] struct {
+	node  *Node[T]
+	tree  *Tree[T]
+	index int // position of the tree in the arguments, used to break ties
+}
+
+// unionHeap is a min-heap of cursors ordered by key, then by tree index.
+type unionHeap[T any] struct {
+	items   []unionCursor[T]
+	compare CompareFunc[T]
+}
+
+func (h *unionHeap[T]) Len() int { return len(h.items) }
+
+func (h *unionHeap[T]) Less(i, j int) bool {
+	if cmp := h.compare(h.items[i].node.key, h.items[j].node.key); cmp != 0 {
+		return cmp < 0
+	}
+
+	return h.items[i].index < h.items[j].index
+}
+
+func (h *unionHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *unionHeap[T]) Push(x any) { h.items = append(h.items, x.(unionCursor[T])) }
+
+func (h *unionHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+
+	return last
+}
+
+// GroupBy partitions the elements of t into trees keyed by groupFn. Each group
+// keeps its elements in ascending order and shares the comparator and options of t.
+// The tree is walked once and every group is bulk-built from its already sorted
+// elements in O(n) total, with no comparisons or rotations. The tree t is left intact.
+func GroupBy[T any, G comparable](t *Tree[T], groupFn func(T) G) map[G]*Tree[T] {
+	groups := make(map[G][]T)
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		group := groupFn(node.key)
+		groups[group] = append(groups[group], node.key)
+	}
+
+	trees := make(map[G]*Tree[T], len(groups))
+	for group, keys := range groups {
+		tree := t.emptyCopy()
+		tree.buildSorted(keys)
+		trees[group] = tree
+	}
+
+	return trees
+}
+
+// Jaccard returns the Jaccard index |A∩B| / |A∪B| of the two trees with multiset
+// semantics: a key present x times in a and y times in b contributes min(x, y)
+// to the intersection and max(x, y) to the union. The intersection is counted in
+// a single merged walk and the union follows from the sizes, so no result trees
+// are built. It runs in O(n+m) time, using the comparator of a.
+// Two empty trees are identical, so their index is 1.
+func Jaccard[T any](a, b *Tree[T]) float64 {
+	if a.root == a.nil && b.root == b.nil {
+		return 1
+	}
+
+	intersection := IntersectionCount(a, b)
+	union := a.root.size + b.root.size - intersection
+
+	return float64(intersection) / float64(union)
+}
+
+// Diff compares two snapshots of a tree and returns, in ascending order, the keys
+// present only in after (added) and only in before (removed), with multiset
+// semantics: a key whose count changes from x to y appears |y-x| times in one
+// of the results. It is a single merged walk in O(n+m) time using the
+// comparator of before, and both trees are left intact.
+func Diff[T any](before, after *Tree[T]) (added, removed []T) {
+	Zip(before, after)(func(key T, origin Origin) bool {
+		switch origin {
+		case InA:
+			removed = append(removed, key)
+		case InB:
+			added = append(added, key)
+		case InBoth:
+		}
+
+		return true
+	})
+
+	return added, removed
+}
+
+// MergeIter returns an iterator over all elements of both trees in ascending order,
+// including duplicates from both, without building a result tree. It walks two
+// in-order cursors in O(n+m) time and O(1) extra space; on ties, elements of a
+// come first. It is the streaming counterpart of AddFrom and UnionAll.
+//
+// Both trees must use compatible comparators; the comparator of a is used.
+// The iterator follows the iter.Seq convention and stops when yield returns false.
+// Neither tree may be modified during iteration.
+func MergeIter[T any](a, b *Tree[T]) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		nodeA, nodeB := a.first(), b.first()
+		for nodeA != a.nil || nodeB != b.nil {
+			var key T
+			if nodeB == b.nil || nodeA != a.nil && a.compare(nodeB.key, nodeA.key) >= 0 {
+				key = nodeA.key
+				nodeA = a.successor(nodeA)
+			} else {
+				key = nodeB.key
+				nodeB = b.successor(nodeB)
+			}
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
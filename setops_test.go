@@ -0,0 +1,632 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAddFrom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sums_multiplicities", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{5, 3, 7, 3})
+		other := buildTree([]int{3, 7, 9, 1, 7})
+
+		tree.AddFrom(other)
+
+		want := []int{1, 3, 3, 3, 5, 7, 7, 7, 9}
+		if got := tree.appendInOrder(nil); !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("leaves_other_intact", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2})
+		other := buildTree([]int{2, 3, 4})
+
+		tree.AddFrom(other)
+
+		if got := other.appendInOrder(nil); !slices.Equal(got, []int{2, 3, 4}) {
+			t.Errorf("other elements = %v, want [2 3 4]", got)
+		}
+
+		checkRedBlackProperties(t, other)
+		verifySizes(t, other.root, other.nil)
+	})
+
+	t.Run("empty_trees", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{4, 2})
+		tree.AddFrom(buildTree(nil))
+		if size := tree.Size(); size != 2 {
+			t.Errorf("Size() after adding empty tree = %d, want 2", size)
+		}
+
+		empty := buildTree(nil)
+		empty.AddFrom(tree)
+		if got := empty.appendInOrder(nil); !slices.Equal(got, []int{2, 4}) {
+			t.Errorf("elements = %v, want [2 4]", got)
+		}
+
+		checkRedBlackProperties(t, empty)
+	})
+
+	t.Run("add_from_self", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		tree.AddFrom(tree)
+
+		if got := tree.appendInOrder(nil); !slices.Equal(got, []int{1, 1, 2, 2, 3, 3}) {
+			t.Errorf("elements = %v, want [1 1 2 2 3 3]", got)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("large_merge_stays_balanced", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		other := NewTree[int](func(a, b int) int { return a - b })
+		for i := 0; i < 1000; i++ {
+			tree.Insert(i * 2)
+			other.Insert(i * 3)
+		}
+
+		tree.AddFrom(other)
+
+		if size := tree.Size(); size != 2000 {
+			t.Errorf("Size() = %d, want 2000", size)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+		verifyTreeIntegrity(t, tree)
+	})
+}
+
+func TestIntersectionCount(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		a, b     []int
+		expected int
+	}{
+		{"both_empty", nil, nil, 0},
+		{"one_empty", []int{1, 2, 3}, nil, 0},
+		{"disjoint", []int{1, 3, 5}, []int{2, 4, 6}, 0},
+		{"partial", []int{1, 2, 3, 4}, []int{3, 4, 5, 6}, 2},
+		{"identical", []int{1, 2, 3}, []int{3, 2, 1}, 3},
+		{"duplicates_min_count", []int{1, 1, 1, 2, 2}, []int{1, 1, 2, 2, 2, 3}, 4},
+		{"subset", []int{5}, []int{1, 2, 3, 4, 5, 6}, 1},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := buildTree(tc.a), buildTree(tc.b)
+
+			if got := IntersectionCount(a, b); got != tc.expected {
+				t.Errorf("IntersectionCount(a, b) = %d, want %d", got, tc.expected)
+			}
+			if got := IntersectionCount(b, a); got != tc.expected {
+				t.Errorf("IntersectionCount(b, a) = %d, want %d", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestZip(t *testing.T) {
+	t.Parallel()
+
+	type pair struct {
+		key    int
+		origin Origin
+	}
+
+	collect := func(a, b *Tree[int]) []pair {
+		var pairs []pair
+		Zip(a, b)(func(key int, origin Origin) bool {
+			pairs = append(pairs, pair{key: key, origin: origin})
+
+			return true
+		})
+
+		return pairs
+	}
+
+	testCases := []struct {
+		name     string
+		a, b     []int
+		expected []pair
+	}{
+		{"both_empty", nil, nil, nil},
+		{"only_a", []int{2, 1}, nil, []pair{{1, InA}, {2, InA}}},
+		{"only_b", nil, []int{2, 1}, []pair{{1, InB}, {2, InB}}},
+		{
+			"disjoint",
+			[]int{1, 3, 5},
+			[]int{2, 4},
+			[]pair{{1, InA}, {2, InB}, {3, InA}, {4, InB}, {5, InA}},
+		},
+		{
+			"overlapping",
+			[]int{1, 2, 3, 4},
+			[]int{3, 4, 5},
+			[]pair{{1, InA}, {2, InA}, {3, InBoth}, {4, InBoth}, {5, InB}},
+		},
+		{
+			"duplicates",
+			[]int{1, 1, 1, 2},
+			[]int{1, 2, 2},
+			[]pair{{1, InBoth}, {1, InA}, {1, InA}, {2, InBoth}, {2, InB}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := collect(buildTree(tc.a), buildTree(tc.b)); !slices.Equal(got, tc.expected) {
+				t.Errorf("Zip() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		a, b := buildTree([]int{1, 3, 5, 7}), buildTree([]int{2, 4, 6, 8})
+
+		var keys []int
+		Zip(a, b)(func(key int, _ Origin) bool {
+			keys = append(keys, key)
+
+			return len(keys) < 3
+		})
+
+		if !slices.Equal(keys, []int{1, 2, 3}) {
+			t.Errorf("keys = %v, want [1 2 3]", keys)
+		}
+	})
+}
+
+func TestRetainAll(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		tree, other     []int
+		expected        []int
+		expectedRemoved int
+	}{
+		{"both_empty", nil, nil, []int{}, 0},
+		{"other_empty", []int{1, 2, 3}, nil, []int{}, 3},
+		{"disjoint", []int{1, 3, 5}, []int{2, 4}, []int{}, 3},
+		{"partial", []int{1, 2, 3, 4, 5}, []int{2, 4, 6}, []int{2, 4}, 3},
+		{"identical", []int{3, 1, 2}, []int{1, 2, 3}, []int{1, 2, 3}, 0},
+		{"capped_by_other_count", []int{1, 1, 1, 2, 2}, []int{1, 2, 2, 2}, []int{1, 2, 2}, 2},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, other := buildTree(tc.tree), buildTree(tc.other)
+
+			if removed := tree.RetainAll(other); removed != tc.expectedRemoved {
+				t.Errorf("RetainAll() = %d, want %d", removed, tc.expectedRemoved)
+			}
+			if got := tree.appendInOrder([]int{}); !slices.Equal(got, tc.expected) {
+				t.Errorf("elements = %v, want %v", got, tc.expected)
+			}
+			if got := other.appendInOrder([]int{}); len(got) != len(tc.other) {
+				t.Errorf("other changed: %v", got)
+			}
+
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		})
+	}
+
+	t.Run("large_tree_stays_balanced", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		other := NewTree[int](func(a, b int) int { return a - b })
+		for i := 0; i < 3000; i++ {
+			tree.Insert(i)
+			if i%3 == 0 {
+				other.Insert(i)
+			}
+		}
+
+		if removed := tree.RetainAll(other); removed != 2000 {
+			t.Errorf("RetainAll() = %d, want 2000", removed)
+		}
+		if size := tree.Size(); size != 1000 {
+			t.Errorf("Size() = %d, want 1000", size)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+		verifyTreeIntegrity(t, tree)
+	})
+}
+
+func TestUnionAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no_trees", func(t *testing.T) {
+		t.Parallel()
+
+		if got := UnionAll[int]().Size(); got != 0 {
+			t.Errorf("Size() = %d, want 0", got)
+		}
+	})
+
+	t.Run("single_tree_is_clone", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{4, 2, 6})
+		result := UnionAll(tree)
+		result.Insert(1)
+
+		if got, want := tree.appendInOrder(nil), []int{2, 4, 6}; !slices.Equal(got, want) {
+			t.Errorf("original = %v, want %v", got, want)
+		}
+		if got, want := result.appendInOrder(nil), []int{1, 2, 4, 6}; !slices.Equal(got, want) {
+			t.Errorf("result = %v, want %v", got, want)
+		}
+	})
+
+	testCases := []struct {
+		name  string
+		trees [][]int
+		want  []int
+	}{
+		{
+			name:  "disjoint",
+			trees: [][]int{{1, 2, 3}, {10, 11}, {4, 5, 6}},
+			want:  []int{1, 2, 3, 4, 5, 6, 10, 11},
+		},
+		{
+			name:  "overlapping",
+			trees: [][]int{{1, 5, 9}, {5, 5, 7}, {0, 9, 12}, {5}},
+			want:  []int{0, 1, 5, 5, 5, 5, 7, 9, 9, 12},
+		},
+		{
+			name:  "with_empty_trees",
+			trees: [][]int{{}, {3, 1}, {}, {2}},
+			want:  []int{1, 2, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			trees := make([]*Tree[int], 0, len(tc.trees))
+			for _, values := range tc.trees {
+				trees = append(trees, buildTree(values))
+			}
+
+			result := UnionAll(trees...)
+			if got := result.appendInOrder(nil); !slices.Equal(got, tc.want) {
+				t.Errorf("elements = %v, want %v", got, tc.want)
+			}
+			checkRedBlackProperties(t, result)
+			verifySizes(t, result.root, result.nil)
+
+			for i, values := range tc.trees {
+				if got := trees[i].Size(); got != len(values) {
+					t.Errorf("tree %d size = %d, want %d", i, got, len(values))
+				}
+			}
+		})
+	}
+
+	t.Run("ties_keep_tree_order", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct {
+			key, tree int
+		}
+		compare := func(a, b item) int { return a.key - b.key }
+
+		trees := make([]*Tree[item], 3)
+		for i := range trees {
+			trees[i] = NewTree(compare)
+			trees[i].Insert(item{key: 1, tree: i})
+			trees[i].Insert(item{key: 2, tree: i})
+		}
+
+		want := []item{{1, 2}, {1, 0}, {1, 1}, {2, 2}, {2, 0}, {2, 1}}
+		if got := UnionAll(trees[2], trees[0], trees[1]).appendInOrder(nil); !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestUnionStable(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		key     int
+		payload string
+	}
+	byKey := func(a, b item) int { return a.key - b.key }
+	newTree := func(items ...item) *Tree[item] {
+		tree := NewTree(byKey)
+		for _, it := range items {
+			tree.Insert(it)
+		}
+
+		return tree
+	}
+
+	a := newTree(item{1, "a1"}, item{2, "a2"}, item{2, "a2'"}, item{4, "a4"})
+	b := newTree(item{2, "b2"}, item{3, "b3"}, item{4, "b4"}, item{4, "b4'"})
+	want := []item{{1, "a1"}, {2, "a2"}, {2, "a2'"}, {2, "b2"}, {3, "b3"}, {4, "a4"}, {4, "b4"}, {4, "b4'"}}
+
+	union := UnionStable(a, b)
+	if got := union.SortedSnapshot(); !slices.Equal(got, want) {
+		t.Errorf("UnionStable() = %v, want %v", got, want)
+	}
+	for i, it := range want {
+		if got, _ := union.Select(i); got != it {
+			t.Errorf("Select(%d) = %v, want %v", i, got, it)
+		}
+	}
+	checkRedBlackProperties(t, union)
+	verifySizes(t, union.root, union.nil)
+
+	if a.Size() != 4 || b.Size() != 4 {
+		t.Error("UnionStable() modified its inputs")
+	}
+	if got := UnionAll(a, b).SortedSnapshot(); !slices.Equal(got, want) {
+		t.Errorf("UnionAll() = %v, want the UnionStable order %v", got, want)
+	}
+	a.AddFrom(b)
+	if got := a.SortedSnapshot(); !slices.Equal(got, want) {
+		t.Errorf("AddFrom() = %v, want the UnionStable order %v", got, want)
+	}
+
+	if got := UnionStable(newTree(), newTree()); got.Size() != 0 {
+		t.Errorf("UnionStable() of empty trees has size %d, want 0", got.Size())
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if groups := GroupBy(tree, func(v int) bool { return v%2 == 0 }); len(groups) != 0 {
+			t.Errorf("GroupBy() returned %d groups, want 0", len(groups))
+		}
+	})
+
+	t.Run("by_parity", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{9, 4, 7, 2, 2, 5, 8, 1, 6, 3, 0, 7})
+		groups := GroupBy(tree, func(v int) string {
+			if v%2 == 0 {
+				return "even"
+			}
+
+			return "odd"
+		})
+
+		want := map[string][]int{
+			"even": {0, 2, 2, 4, 6, 8},
+			"odd":  {1, 3, 5, 7, 7, 9},
+		}
+		if len(groups) != len(want) {
+			t.Fatalf("GroupBy() returned %d groups, want %d", len(groups), len(want))
+		}
+		for name, values := range want {
+			group := groups[name]
+			if got := group.SortedSnapshot(); !slices.Equal(got, values) {
+				t.Errorf("group %q = %v, want %v", name, got, values)
+			}
+			checkRedBlackProperties(t, group)
+			verifySizes(t, group.root, group.nil)
+		}
+
+		if tree.Size() != 12 {
+			t.Errorf("original Size() = %d, want 12", tree.Size())
+		}
+	})
+
+	t.Run("groups_share_comparator", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return b - a }) // descending
+		for _, v := range []int{1, 2, 3, 4, 5, 6} {
+			tree.Insert(v)
+		}
+
+		group := GroupBy(tree, func(v int) int { return v % 3 })[0]
+		group.Insert(9)
+		group.Insert(0)
+		if got, want := group.SortedSnapshot(), []int{9, 6, 3, 0}; !slices.Equal(got, want) {
+			t.Errorf("group = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestJaccard(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		a, b []int
+		want float64
+	}{
+		{name: "both_empty", a: nil, b: nil, want: 1},
+		{name: "one_empty", a: []int{1, 2}, b: nil, want: 0},
+		{name: "identical", a: []int{1, 2, 3}, b: []int{3, 2, 1}, want: 1},
+		{name: "disjoint", a: []int{1, 2}, b: []int{3, 4}, want: 0},
+		{name: "overlapping", a: []int{1, 2, 3, 4}, b: []int{3, 4, 5, 6}, want: 2.0 / 6.0},
+		// intersection {2, 2}, union {1, 2, 2, 2}
+		{name: "multiset", a: []int{1, 2, 2, 2}, b: []int{2, 2}, want: 2.0 / 4.0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := buildTree(tc.a), buildTree(tc.b)
+			if got := Jaccard(a, b); got != tc.want {
+				t.Errorf("Jaccard(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+			if got := Jaccard(b, a); got != tc.want {
+				t.Errorf("Jaccard(%v, %v) = %v, want %v", tc.b, tc.a, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		before      []int
+		after       []int
+		wantAdded   []int
+		wantRemoved []int
+	}{
+		{name: "both_empty", before: nil, after: nil, wantAdded: nil, wantRemoved: nil},
+		{name: "identical", before: []int{1, 2, 3}, after: []int{3, 2, 1}, wantAdded: nil, wantRemoved: nil},
+		{name: "insertions", before: []int{2, 4}, after: []int{1, 2, 3, 4, 5}, wantAdded: []int{1, 3, 5}, wantRemoved: nil},
+		{name: "deletions", before: []int{1, 2, 3, 4}, after: []int{2}, wantAdded: nil, wantRemoved: []int{1, 3, 4}},
+		{name: "mixed", before: []int{1, 2, 3}, after: []int{2, 3, 4}, wantAdded: []int{4}, wantRemoved: []int{1}},
+		{
+			name:        "duplicate_counts",
+			before:      []int{5, 5, 5, 7, 9},
+			after:       []int{5, 7, 7, 7, 9, 9},
+			wantAdded:   []int{7, 7, 9},
+			wantRemoved: []int{5, 5},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			before, after := buildTree(tc.before), buildTree(tc.after)
+			added, removed := Diff(before, after)
+			if !slices.Equal(added, tc.wantAdded) {
+				t.Errorf("added = %v, want %v", added, tc.wantAdded)
+			}
+			if !slices.Equal(removed, tc.wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, tc.wantRemoved)
+			}
+
+			// Applying the diff to before yields after
+			for _, key := range removed {
+				before.Delete(key)
+			}
+			for _, key := range added {
+				before.Insert(key)
+			}
+			if got, want := before.SortedSnapshot(), after.SortedSnapshot(); !slices.Equal(got, want) {
+				t.Errorf("before with diff applied = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergeIter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		a, b []int
+		want []int
+	}{
+		{name: "both_empty", a: nil, b: nil, want: nil},
+		{name: "one_empty", a: nil, b: []int{2, 1}, want: []int{1, 2}},
+		{name: "disjoint", a: []int{1, 2, 3}, b: []int{7, 8}, want: []int{1, 2, 3, 7, 8}},
+		{name: "interleaved", a: []int{1, 4, 9}, b: []int{2, 3, 10}, want: []int{1, 2, 3, 4, 9, 10}},
+		{name: "overlapping", a: []int{1, 3, 3, 5}, b: []int{3, 5, 6}, want: []int{1, 3, 3, 3, 5, 5, 6}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []int
+			MergeIter(buildTree(tc.a), buildTree(tc.b))(func(key int) bool {
+				got = append(got, key)
+
+				return true
+			})
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("MergeIter() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("ties_from_a_first", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct {
+			key  int
+			from string
+		}
+		compare := func(x, y item) int { return x.key - y.key }
+		a, b := NewTree(compare), NewTree(compare)
+		a.Insert(item{1, "a"})
+		b.Insert(item{1, "b"})
+		a.Insert(item{2, "a"})
+
+		var got []item
+		MergeIter(a, b)(func(key item) bool {
+			got = append(got, key)
+
+			return true
+		})
+		if want := []item{{1, "a"}, {1, "b"}, {2, "a"}}; !slices.Equal(got, want) {
+			t.Errorf("MergeIter() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("early_termination", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		MergeIter(buildTree([]int{1, 3, 5}), buildTree([]int{2, 4}))(func(int) bool {
+			calls++
+
+			return calls < 3
+		})
+		if calls != 3 {
+			t.Errorf("yield called %d times, want 3", calls)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package gostree
+
+// TopK retains the k largest elements offered to it, such as the best scores
+// of a leaderboard. Each Offer takes O(log k) time.
+// It is a thin facade over a Tree ordered by the given comparison function.
+type TopK[T any] struct {
+	tree *Tree[T]
+	k    int
+}
+
+// NewTopK creates an empty TopK that keeps the k largest elements according to
+// compare. It panics if k < 1.
+func NewTopK[T any](k int, compare CompareFunc[T]) *TopK[T] {
+	if k < 1 {
+		panic("gostree: TopK size must be positive")
+	}
+
+	return &TopK[T]{
+		tree: NewTree(compare),
+		k:    k,
+	}
+}
+
+// Offer inserts x and, if more than k elements are now held, removes the smallest
+// and returns it with true. The evicted element may be x itself when x is
+// smaller than the current smallest of a full TopK. Among equal elements, the one
+// that has been held longest is evicted first.
+func (tk *TopK[T]) Offer(x T) (evicted T, didEvict bool) {
+	tk.tree.Insert(x)
+	if tk.tree.Size() <= tk.k {
+		return evicted, false
+	}
+
+	node := tk.tree.first()
+	tk.tree.deleteNode(node)
+
+	return node.key, true
+}
+
+// Len returns the number of elements held, at most k.
+func (tk *TopK[T]) Len() int {
+	return tk.tree.Size()
+}
+
+// Items returns the held elements in descending order.
+func (tk *TopK[T]) Items() []T {
+	items := make([]T, 0, tk.tree.Size())
+	for node := tk.tree.maximum(tk.tree.root); node != tk.tree.nil; node = tk.tree.predecessor(node) {
+		items = append(items, node.key)
+	}
+
+	return items
+}
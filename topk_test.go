@@ -0,0 +1,77 @@
+package gostree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retains_largest", func(t *testing.T) {
+		t.Parallel()
+
+		tk := NewTopK[int](3, func(a, b int) int { return a - b })
+		var evicted []int
+		for _, score := range []int{50, 20, 80, 10, 90, 60, 70} {
+			if x, ok := tk.Offer(score); ok {
+				evicted = append(evicted, x)
+			}
+		}
+
+		if got, want := tk.Items(), []int{90, 80, 70}; !slices.Equal(got, want) {
+			t.Errorf("Items() = %v, want %v", got, want)
+		}
+		if got, want := evicted, []int{10, 20, 50, 60}; !slices.Equal(got, want) {
+			t.Errorf("evicted = %v, want %v", got, want)
+		}
+		if tk.Len() != 3 {
+			t.Errorf("Len() = %d, want 3", tk.Len())
+		}
+		checkRedBlackProperties(t, tk.tree)
+	})
+
+	t.Run("not_full", func(t *testing.T) {
+		t.Parallel()
+
+		tk := NewTopK[int](5, func(a, b int) int { return a - b })
+		if got := tk.Items(); len(got) != 0 {
+			t.Errorf("Items() on empty = %v, want empty", got)
+		}
+		for _, score := range []int{3, 1, 2} {
+			if x, ok := tk.Offer(score); ok {
+				t.Errorf("Offer(%d) evicted %d before reaching k", score, x)
+			}
+		}
+		if got, want := tk.Items(), []int{3, 2, 1}; !slices.Equal(got, want) {
+			t.Errorf("Items() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ties_evict_oldest", func(t *testing.T) {
+		t.Parallel()
+
+		type entry struct {
+			score int
+			name  string
+		}
+		tk := NewTopK(2, func(a, b entry) int { return a.score - b.score })
+		tk.Offer(entry{5, "first"})
+		tk.Offer(entry{5, "second"})
+		x, ok := tk.Offer(entry{5, "third"})
+		if !ok || x.name != "first" {
+			t.Errorf("Offer() evicted %v, %v, want {5 first}, true", x, ok)
+		}
+	})
+
+	t.Run("invalid_k_panics", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("NewTopK(0) did not panic")
+			}
+		}()
+		NewTopK[int](0, func(a, b int) int { return a - b })
+	})
+}
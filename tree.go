@@ -1,5 +1,12 @@
 package gostree
 
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"sync/atomic"
+)
+
 type Color bool
 
 const (
@@ -24,9 +31,25 @@ type Node[T any] struct {
 }
 
 type Tree[T any] struct {
-	root    *Node[T]
-	nil     *Node[T] // sentinel node
-	compare CompareFunc[T]
+	root         *Node[T]
+	nil          *Node[T] // sentinel node
+	compare      CompareFunc[T]
+	compareCalls *atomic.Uint64 // nil unless WithComparatorCounter is set
+	maxSize      int            // 0 means unbounded
+	evictPolicy  EvictPolicy
+	encodeKey    func(T) []byte           // nil unless WithCodec is set
+	decodeKey    func([]byte) (T, error)  // nil unless WithCodec is set
+	rotationHook func(RotationKind, T)    // nil unless WithRotationHook is set
+	metrics      *opCounters              // nil unless WithMetrics is set
+	sortedAppend bool                     // set by WithSortedAppend
+	maxNode      *Node[T]                 // cached maximum in sorted-append mode, nil if unknown
+	equal        func(a, b T) bool        // nil unless WithEquality is set
+	fixupHook    func(FixupPhase, int, T) // nil unless WithFixupHook is set
+	reservoir    *rand.Rand               // nil unless WithReservoir is set
+	sampleSize   int                      // reservoir capacity, 0 unless WithReservoir is set
+	offered      int                      // keys offered since the contents were last replaced, in reservoir mode
+	baseCompare  CompareFunc[T]           // comparator given to NewTree, before options wrap it
+	options      []Option[T]              // options given to NewTree, replayed by emptyCopy
 }
 
 // getGrandparent returns the grandparent of the node
@@ -74,34 +97,98 @@ func (n *Node[T]) isRightChild() bool {
 }
 
 // NewTree creates a new order-statistic tree.
-func NewTree[T any](compare CompareFunc[T]) *Tree[T] {
+func NewTree[T any](compare CompareFunc[T], opts ...Option[T]) *Tree[T] {
 	t := &Tree[T]{
-		root:    nil,
-		compare: compare,
-		nil: &Node[T]{ // sentinel node
-			key:    *new(T),
-			left:   nil,
-			right:  nil,
-			parent: nil,
-			color:  BLACK,
-			size:   0,
-		},
+		root:         nil,
+		compare:      compare,
+		compareCalls: nil,
+		maxSize:      0,
+		evictPolicy:  EvictSmallest,
+		encodeKey:    nil,
+		decodeKey:    nil,
+		rotationHook: nil,
+		metrics:      nil,
+		sortedAppend: false,
+		maxNode:      nil,
+		equal:        nil,
+		fixupHook:    nil,
+		reservoir:    nil,
+		sampleSize:   0,
+		offered:      0,
+		baseCompare:  compare,
+		options:      slices.Clone(opts),
+		nil:          newSentinel[T](),
 	}
 
-	// Make sentinel self-referential
-	t.nil.left = t.nil
-	t.nil.right = t.nil
-	t.nil.parent = t.nil
-
 	// Initialize root to sentinel
 	t.root = t.nil
 
+	for _, opt := range opts {
+		opt(t)
+	}
+
 	return t
 }
 
+// newSentinel creates a self-referential BLACK sentinel node
+func newSentinel[T any]() *Node[T] {
+	sentinel := &Node[T]{
+		key:    *new(T),
+		left:   nil,
+		right:  nil,
+		parent: nil,
+		color:  BLACK,
+		size:   0,
+	}
+
+	// Make sentinel self-referential
+	sentinel.left = sentinel
+	sentinel.right = sentinel
+	sentinel.parent = sentinel
+
+	return sentinel
+}
+
 // Insert adds a new key to the red-black tree
 // and maintains the red-black properties.
+// If the tree is bounded with WithMaxSize and full, an element is evicted first.
 func (t *Tree[T]) Insert(key T) {
+	t.InsertEvict(key)
+}
+
+// InsertEvict is like Insert but also reports the element that was evicted
+// to make room for the key when the tree is bounded with WithMaxSize.
+// It returns false if nothing was evicted. In reservoir mode the reported
+// element may be key itself when it was not sampled.
+func (t *Tree[T]) InsertEvict(key T) (T, bool) {
+	if t.reservoir != nil {
+		return t.sample(key)
+	}
+
+	var evicted T
+	ok := false
+	if t.maxSize > 0 && t.root.size >= t.maxSize {
+		evicted, ok = t.evict()
+	}
+	t.insert(key)
+
+	return evicted, ok
+}
+
+func (t *Tree[T]) insert(key T) {
+	t.checkCapacity()
+
+	if t.sortedAppend && t.root != t.nil {
+		if t.maxNode == nil {
+			t.maxNode = t.maximum(t.root)
+		}
+		if t.compare(key, t.maxNode.key) >= 0 {
+			t.appendAfter(t.maxNode, key)
+
+			return
+		}
+	}
+
 	newNode := &Node[T]{
 		key:    key,
 		left:   t.nil,
@@ -135,11 +222,39 @@ func (t *Tree[T]) Insert(key T) {
 	} else {
 		parent.right = newNode
 	}
+	if t.metrics != nil {
+		t.metrics.inserts.Add(1)
+	}
 
 	// Fix red-black properties
 	t.insertFixup(newNode)
 }
 
+// appendAfter inserts key as the right child of the maximum node, skipping the search
+// descent. The caller must ensure key is not less than maximum's key.
+func (t *Tree[T]) appendAfter(maximum *Node[T], key T) {
+	newNode := &Node[T]{
+		key:    key,
+		left:   t.nil,
+		right:  t.nil,
+		parent: maximum,
+		color:  RED,
+		size:   1,
+	}
+	maximum.right = newNode
+	for node := maximum; node != t.nil; node = node.parent {
+		node.size++
+	}
+	if t.metrics != nil {
+		t.metrics.inserts.Add(1)
+	}
+	t.insertFixup(newNode)
+
+	if t.sortedAppend {
+		t.maxNode = newNode
+	}
+}
+
 // insertFixup maintains red-black tree properties after insertion
 //
 // The function handles violations where a RED node has a RED parent.
@@ -184,6 +299,7 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 				//  P(R)   U(R)  =>    P(B)   U(B)
 				//  /                  /
 				// N(R)               N(R)
+				t.reportFixup(FixupInsert, 1, newNode)
 				parent.color = BLACK
 				uncle.color = BLACK
 				grandparent.color = RED
@@ -196,6 +312,7 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 					//  P(R)   U(B)  =>  N(R)   U(B)
 					//    \              /
 					//     N(R)        P(R)
+					t.reportFixup(FixupInsert, 2, newNode)
 					newNode = parent
 					t.leftRotate(newNode)
 				}
@@ -205,6 +322,7 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 				//  P(R)   U(B)  =>  N(R)   G(R)
 				//  /                        \
 				// N(R)                      U(B)
+				t.reportFixup(FixupInsert, 3, newNode)
 				newNode.parent.color = BLACK
 				grandparent.color = RED
 				t.rightRotate(grandparent)
@@ -219,6 +337,7 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 				//  U(R)   P(R)  =>    U(B)   P(B)
 				//           \                   \
 				//            N(R)                N(R)
+				t.reportFixup(FixupInsert, 1, newNode)
 				parent.color = BLACK
 				uncle.color = BLACK
 				grandparent.color = RED
@@ -231,6 +350,7 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 					//  U(B)   P(R)  =>  U(B)   N(R)
 					//         /                   \
 					//       N(R)                  P(R)
+					t.reportFixup(FixupInsert, 2, newNode)
 					newNode = parent
 					t.rightRotate(newNode)
 				}
@@ -240,6 +360,7 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 				//  U(B)   P(R)  =>  G(R)   N(R)
 				//           \       /
 				//            N(R)  U(B)
+				t.reportFixup(FixupInsert, 3, newNode)
 				newNode.parent.color = BLACK
 				grandparent.color = RED
 				t.leftRotate(grandparent)
@@ -249,6 +370,13 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 	t.root.color = BLACK
 }
 
+// reportFixup passes a rebalancing case to the fixup hook, if one is set
+func (t *Tree[T]) reportFixup(phase FixupPhase, caseNumber int, node *Node[T]) {
+	if t.fixupHook != nil {
+		t.fixupHook(phase, caseNumber, node.key)
+	}
+}
+
 // leftRotate performs a left rotation on the given node
 //
 // Before:         After:
@@ -262,6 +390,13 @@ func (t *Tree[T]) insertFixup(newNode *Node[T]) {
 // Where x = node, y = rightChild
 // Parent relationships are updated accordingly
 func (t *Tree[T]) leftRotate(node *Node[T]) {
+	if t.rotationHook != nil {
+		t.rotationHook(RotateLeft, node.key)
+	}
+	if t.metrics != nil {
+		t.metrics.leftRotations.Add(1)
+	}
+
 	rightChild := node.right
 	node.right = rightChild.left
 	if rightChild.left != t.nil {
@@ -296,6 +431,13 @@ func (t *Tree[T]) leftRotate(node *Node[T]) {
 // Where y = node, x = leftChild
 // Parent relationships are updated accordingly
 func (t *Tree[T]) rightRotate(node *Node[T]) {
+	if t.rotationHook != nil {
+		t.rotationHook(RotateRight, node.key)
+	}
+	if t.metrics != nil {
+		t.metrics.rightRotations.Add(1)
+	}
+
 	leftChild := node.left
 	node.left = leftChild.right
 	if leftChild.right != t.nil {
@@ -319,8 +461,35 @@ func (t *Tree[T]) rightRotate(node *Node[T]) {
 
 // Search checks if a key exists in the tree.
 // It returns true if the key is found, false otherwise.
+// With WithEquality set, an element matches only if it also satisfies the equality function.
 func (t *Tree[T]) Search(key T) bool {
-	return t.search(key) != t.nil
+	if t.metrics != nil {
+		t.metrics.searches.Add(1)
+	}
+
+	return t.find(key) != t.nil
+}
+
+// find returns a node holding key, or the sentinel if there is none.
+// With WithEquality set, it returns the first node in the run of keys
+// that compare equal to key for which the equality function also holds.
+func (t *Tree[T]) find(key T) *Node[T] {
+	if t.equal == nil {
+		return t.search(key)
+	}
+
+	return t.findFirst(key)
+}
+
+// findFirst is like find but always returns the leftmost matching node
+func (t *Tree[T]) findFirst(key T) *Node[T] {
+	for node := t.lowerBound(key); node != t.nil && t.compare(key, node.key) == 0; node = t.successor(node) {
+		if t.equal == nil || t.equal(key, node.key) {
+			return node
+		}
+	}
+
+	return t.nil
 }
 
 func (t *Tree[T]) search(key T) *Node[T] {
@@ -339,6 +508,74 @@ func (t *Tree[T]) search(key T) *Node[T] {
 	return current
 }
 
+// lowerBound returns the leftmost node with key greater than or equal to the given key,
+// or the sentinel if there is none
+func (t *Tree[T]) lowerBound(key T) *Node[T] {
+	result := t.nil
+	current := t.root
+	for current != t.nil {
+		if t.compare(key, current.key) <= 0 {
+			result = current
+			current = current.left
+		} else {
+			current = current.right
+		}
+	}
+
+	return result
+}
+
+// upperBound returns the leftmost node with key strictly greater than the given key,
+// or the sentinel if there is none
+func (t *Tree[T]) upperBound(key T) *Node[T] {
+	result := t.nil
+	current := t.root
+	for current != t.nil {
+		if t.compare(key, current.key) < 0 {
+			result = current
+			current = current.left
+		} else {
+			current = current.right
+		}
+	}
+
+	return result
+}
+
+// floorNode returns the rightmost node with key less than or equal to the given key,
+// or the sentinel if there is none
+func (t *Tree[T]) floorNode(key T) *Node[T] {
+	result := t.nil
+	current := t.root
+	for current != t.nil {
+		if t.compare(key, current.key) >= 0 {
+			result = current
+			current = current.right
+		} else {
+			current = current.left
+		}
+	}
+
+	return result
+}
+
+// lowerNode returns the rightmost node with key strictly less than the given key,
+// or the sentinel if there is none
+func (t *Tree[T]) lowerNode(key T) *Node[T] {
+	result := t.nil
+	current := t.root
+	for current != t.nil {
+		if t.compare(key, current.key) > 0 {
+			result = current
+			current = current.right
+		} else {
+			current = current.left
+		}
+	}
+
+	return result
+}
+
 // Select returns the k-th smallest element (0-indexed).
 func (t *Tree[T]) Select(k int) (T, bool) {
 	var zero T
@@ -387,9 +624,40 @@ func (t *Tree[T]) Rank(key T) int {
 	return rank
 }
 
+// nodeRank returns the rank of node by walking up to the root, without comparisons
+func (t *Tree[T]) nodeRank(node *Node[T]) int {
+	rank := node.left.size
+	for ; node.parent != t.nil; node = node.parent {
+		if node.isRightChild() {
+			rank += node.parent.left.size + 1
+		}
+	}
+
+	return rank
+}
+
+// rankUpper returns the number of elements less than or equal to the given key.
+func (t *Tree[T]) rankUpper(key T) int {
+	rank := 0
+	current := t.root
+
+	for current != t.nil {
+		if t.compare(key, current.key) < 0 {
+			current = current.left
+		} else {
+			rank += current.left.size + 1
+			current = current.right
+		}
+	}
+
+	return rank
+}
+
 // Delete removes one occurrence of a key from the tree.
+// With WithEquality set, only an element equal to key under both the comparator
+// and the equality function is removed.
 func (t *Tree[T]) Delete(key T) bool {
-	nodeToDelete := t.search(key)
+	nodeToDelete := t.find(key)
 	if nodeToDelete == t.nil {
 		return false
 	}
@@ -399,7 +667,104 @@ func (t *Tree[T]) Delete(key T) bool {
 	return true
 }
 
+// DeleteRankRange removes all elements at ranks [i, j) and returns the number removed.
+// Indices are clamped to [0, Size()]; an empty or inverted range removes nothing.
+func (t *Tree[T]) DeleteRankRange(i, j int) int {
+	i = max(i, 0)
+	j = min(j, t.root.size)
+	if i >= j {
+		return 0
+	}
+
+	// Nodes are relinked rather than having keys copied on delete,
+	// so the successor captured before each delete remains valid.
+	node := t.selectNode(t.root, i)
+	for n := i; n < j; n++ {
+		next := t.successor(node)
+		t.deleteNode(node)
+		node = next
+	}
+
+	return j - i
+}
+
+// DeleteManyWithRanks deletes one occurrence of each key, processing keys in the
+// given order, and returns a slice parallel to keys. Each entry is the rank the removed
+// element occupied just before its own deletion, so it accounts for the earlier deletes
+// in the batch (as needed to replay removals from a list), or -1 if the key was absent.
+// The leftmost matching occurrence is removed; with WithEquality set, only elements
+// that also satisfy the equality function match, as in Delete.
+func (t *Tree[T]) DeleteManyWithRanks(keys []T) []int {
+	ranks := make([]int, len(keys))
+	for i, key := range keys {
+		node := t.findFirst(key)
+		if node == t.nil {
+			ranks[i] = -1
+
+			continue
+		}
+		ranks[i] = t.nodeRank(node)
+		t.deleteNode(node)
+	}
+
+	return ranks
+}
+
+// DeleteRandom removes an element chosen uniformly at random using r
+// and returns it, or false if the tree is empty. The element is located by rank,
+// so each element, including each copy of a duplicate key, is equally likely.
+func (t *Tree[T]) DeleteRandom(r *rand.Rand) (T, bool) {
+	if t.root == t.nil {
+		var zero T
+
+		return zero, false
+	}
+
+	node := t.selectNode(t.root, r.Intn(t.root.size))
+	key := node.key
+	t.deleteNode(node)
+
+	return key, true
+}
+
+// WalkAndPrune visits the keys in ascending order and deletes each key for which
+// fn reports delete, stopping after the first key for which fn reports stop.
+// Unlike deleting from inside a range iterator, this is safe: the successor is
+// found before the current node is removed, and deletion relinks nodes rather
+// than moving keys between them. fn must not modify the tree itself.
+func (t *Tree[T]) WalkAndPrune(fn func(key T) (del, stop bool)) {
+	for node := t.first(); node != t.nil; {
+		next := t.successor(node)
+		del, stop := fn(node.key)
+		if del {
+			t.deleteNode(node)
+		}
+		if stop {
+			return
+		}
+		node = next
+	}
+}
+
+// Update replaces one occurrence of oldKey with newKey, repositioning it in the tree.
+// It returns false, leaving the tree unchanged, if oldKey is not present.
+func (t *Tree[T]) Update(oldKey, newKey T) bool {
+	if !t.Delete(oldKey) {
+		return false
+	}
+	t.Insert(newKey)
+
+	return true
+}
+
 func (t *Tree[T]) deleteNode(nodeToDelete *Node[T]) {
+	if t.metrics != nil {
+		t.metrics.deletes.Add(1)
+	}
+	if nodeToDelete == t.maxNode {
+		t.maxNode = nil
+	}
+
 	nodeActuallyDeleted := nodeToDelete
 	originalColor := nodeActuallyDeleted.color
 	var replacementNode *Node[T]
@@ -430,10 +795,13 @@ func (t *Tree[T]) deleteNode(nodeToDelete *Node[T]) {
 		nodeActuallyDeleted.left = nodeToDelete.left
 		nodeActuallyDeleted.left.parent = nodeActuallyDeleted
 		nodeActuallyDeleted.color = nodeToDelete.color
+		// Successor takes over the deleted node's position, including its subtree size
+		nodeActuallyDeleted.size = nodeToDelete.size
 	}
 
-	// Update sizes from the deletion point upward
-	t.updateSizeUpward(replacementNode.parent)
+	// Every node from the removal point up to the root lost exactly one descendant.
+	// Fixup rotations recompute sizes from children, so this must happen first.
+	t.decrementSizeUpward(replacementNode.parent)
 
 	if originalColor == BLACK {
 		t.deleteFixup(replacementNode)
@@ -472,10 +840,65 @@ func (t *Tree[T]) minimum(node *Node[T]) *Node[T] {
 	return node
 }
 
-// updateSizeUpward recalculates sizes from node to root
-func (t *Tree[T]) updateSizeUpward(node *Node[T]) {
+// maximum returns the node with maximum key in subtree rooted at the given node
+func (t *Tree[T]) maximum(node *Node[T]) *Node[T] {
+	for node.right != t.nil {
+		node = node.right
+	}
+
+	return node
+}
+
+// successor returns the next node in in-order sequence, or the sentinel if there is none
+func (t *Tree[T]) successor(node *Node[T]) *Node[T] {
+	if node.right != t.nil {
+		return t.minimum(node.right)
+	}
+	parent := node.parent
+	for parent != t.nil && node == parent.right {
+		node = parent
+		parent = parent.parent
+	}
+
+	return parent
+}
+
+// predecessor returns the previous node in in-order sequence, or the sentinel if there is none
+func (t *Tree[T]) predecessor(node *Node[T]) *Node[T] {
+	if node.left != t.nil {
+		return t.maximum(node.left)
+	}
+	parent := node.parent
+	for parent != t.nil && node == parent.left {
+		node = parent
+		parent = parent.parent
+	}
+
+	return parent
+}
+
+// first returns the node with the smallest key, or the sentinel if the tree is empty
+func (t *Tree[T]) first() *Node[T] {
+	if t.root == t.nil {
+		return t.nil
+	}
+
+	return t.minimum(t.root)
+}
+
+// appendInOrder appends all keys in ascending order to dst and returns the extended slice
+func (t *Tree[T]) appendInOrder(dst []T) []T {
+	for node := t.first(); node != t.nil; node = t.successor(node) {
+		dst = append(dst, node.key)
+	}
+
+	return dst
+}
+
+// decrementSizeUpward decrements sizes from node to root
+func (t *Tree[T]) decrementSizeUpward(node *Node[T]) {
 	for node != t.nil {
-		node.size = node.left.size + node.right.size + 1
+		node.size--
 		node = node.parent
 	}
 }
@@ -531,6 +954,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 				// N(B)   S(R)  =>  P(R)   SR(B)
 				//       /   \      /   \
 				//     SL(B) SR(B) N(B) SL(B)
+				t.reportFixup(FixupDelete, 1, node)
 				sibling.color = BLACK
 				node.parent.color = RED
 				t.leftRotate(node.parent)
@@ -543,6 +967,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 				// N(B)   S(B)  =>  N(B)   S(R)
 				//       /   \            /   \
 				//     SL(B) SR(B)      SL(B) SR(B)
+				t.reportFixup(FixupDelete, 2, node)
 				sibling.color = RED
 				node = node.parent
 			} else {
@@ -555,6 +980,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 					//     SL(R) SR(B)            S(R)
 					//                              \
 					//                             SR(B)
+					t.reportFixup(FixupDelete, 3, node)
 					sibling.left.color = BLACK
 					sibling.color = RED
 					t.rightRotate(sibling)
@@ -566,6 +992,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 				// N(B)   S(B)  =>  P(B)   SR(B)
 				//       /   \      /   \
 				//     SL(?) SR(R) N(B) SL(?)
+				t.reportFixup(FixupDelete, 4, node)
 				sibling.color = node.parent.color
 				node.parent.color = BLACK
 				sibling.right.color = BLACK
@@ -582,6 +1009,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 				//    S(R)   N(B)  =>  SL(B)  P(R)
 				//   /   \                   /   \
 				// SL(B) SR(B)             SR(B) N(B)
+				t.reportFixup(FixupDelete, 1, node)
 				sibling.color = BLACK
 				node.parent.color = RED
 				t.rightRotate(node.parent)
@@ -594,6 +1022,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 				//    S(B)   N(B)  =>  S(R)   N(B)
 				//   /   \            /   \
 				// SL(B) SR(B)      SL(B) SR(B)
+				t.reportFixup(FixupDelete, 2, node)
 				sibling.color = RED
 				node = node.parent
 			} else {
@@ -606,6 +1035,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 					// SL(B) SR(R)       S(R)
 					//                  /
 					//                SL(B)
+					t.reportFixup(FixupDelete, 3, node)
 					sibling.right.color = BLACK
 					sibling.color = RED
 					t.leftRotate(sibling)
@@ -617,6 +1047,7 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 				//    S(B)   N(B)  =>  SL(B)  P(B)
 				//   /   \                   /   \
 				// SL(R) SR(?)             SR(?) N(B)
+				t.reportFixup(FixupDelete, 4, node)
 				sibling.color = node.parent.color
 				node.parent.color = BLACK
 				sibling.left.color = BLACK
@@ -629,6 +1060,68 @@ func (t *Tree[T]) deleteFixup(node *Node[T]) {
 }
 
 // Size returns the number of elements in the tree.
+// A tree holds at most math.MaxInt elements (about 2^31 on 32-bit platforms);
+// inserting beyond that panics rather than corrupting order statistics.
 func (t *Tree[T]) Size() int {
 	return t.root.size
 }
+
+// checkCapacity panics if adding an element would overflow the size counters
+func (t *Tree[T]) checkCapacity() {
+	if t.root.size == math.MaxInt {
+		panic("gostree: tree size would overflow int")
+	}
+}
+
+// SortedSnapshot returns a copy of all elements in ascending order.
+//
+// The slice is a point-in-time copy that is independent of the tree: it does not
+// reflect later modifications and may be iterated freely while the tree is mutated.
+// Taking the snapshot itself is a read operation and must be synchronized with writers.
+func (t *Tree[T]) SortedSnapshot() []T {
+	return t.appendInOrder(make([]T, 0, t.root.size))
+}
+
+// Drain removes all elements from the tree and returns them in ascending order.
+func (t *Tree[T]) Drain() []T {
+	keys := t.SortedSnapshot()
+	t.removeAll()
+
+	return keys
+}
+
+// Reset replaces the contents of the tree with values, keeping the comparator and
+// options. The values are copied, stably sorted and bulk-built in O(n log n), which
+// is faster than removing all elements and inserting them one by one. The values
+// slice is not modified. A tree bounded with WithMaxSize keeps the largest elements
+// under EvictSmallest and the smallest elements under EvictLargest. A tree in
+// reservoir mode keeps a uniform random sample of values, as if they had been
+// inserted one by one into an empty tree.
+func (t *Tree[T]) Reset(values []T) {
+	var sorted []T
+	if t.reservoir != nil && len(values) > t.sampleSize {
+		sorted = t.sampleOf(values)
+	} else {
+		sorted = slices.Clone(values)
+	}
+	slices.SortStableFunc(sorted, t.compare)
+
+	t.buildSorted(sorted)
+	t.offered = len(values)
+}
+
+// Swap exchanges the contents of the two trees in O(1), e.g. to replace an index
+// built in the background. The comparison function and options move together with
+// the elements they belong to. Neither tree may be in use by other goroutines.
+func (t *Tree[T]) Swap(other *Tree[T]) {
+	*t, *other = *other, *t
+}
+
+// removeAll resets the tree to empty, dropping all references to existing nodes
+func (t *Tree[T]) removeAll() {
+	t.root = t.nil
+	t.maxNode = nil
+	t.offered = 0
+	// The sentinel's parent may be left pointing at a node after deletions
+	t.nil.parent = t.nil
+}
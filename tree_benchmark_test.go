@@ -238,6 +238,38 @@ func BenchmarkDelete(b *testing.B) {
 	}
 }
 
+// BenchmarkDeleteAll measures a delete-heavy workload that empties the whole tree
+func BenchmarkDeleteAll(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		size int
+	}{
+		{"1000_elements", 1000},
+		{"10000_elements", 10000},
+		{"100000_elements", 100000},
+	}
+
+	for _, bm := range benchmarks {
+		data := generateRandomData(bm.size)
+
+		b.Run("krzysztofgb/gostree/"+bm.name, func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				tree := NewTree[int](func(a, b int) int { return a - b })
+				for _, v := range data {
+					tree.Insert(v)
+				}
+				b.StartTimer()
+
+				for _, v := range data {
+					tree.Delete(v)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkRank(b *testing.B) {
 	benchmarks := []struct {
 		name string
@@ -384,3 +416,161 @@ func BenchmarkMixedOperations(b *testing.B) {
 		})
 	}
 }
+
+// balancedOrder returns the keys 0..n-1 in level order of a perfectly balanced tree,
+// so that every key is inserted after its ancestors and few rotations are needed
+func balancedOrder(n int) []int {
+	type span struct{ lo, hi int }
+
+	order := make([]int, 0, n)
+	queue := []span{{lo: 0, hi: n}}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s.lo >= s.hi {
+			continue
+		}
+		mid := (s.lo + s.hi) / 2
+		order = append(order, mid)
+		queue = append(queue, span{lo: s.lo, hi: mid}, span{lo: mid + 1, hi: s.hi})
+	}
+
+	return order
+}
+
+// BenchmarkInsertRotations compares insert sequences that trigger many rotations
+// (strictly increasing keys) with sequences that need few (balanced level order)
+func BenchmarkInsertRotations(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		size int
+	}{
+		{"1000_elements", 1000},
+		{"10000_elements", 10000},
+	}
+
+	for _, bm := range benchmarks {
+		increasing := make([]int, bm.size)
+		for i := range increasing {
+			increasing[i] = i
+		}
+		balanced := balancedOrder(bm.size)
+
+		sequences := []struct {
+			name string
+			data []int
+		}{
+			{"increasing", increasing},
+			{"balanced", balanced},
+		}
+
+		for _, seq := range sequences {
+			b.Run(seq.name+"/"+bm.name, func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					tree := NewTree[int](func(a, b int) int { return a - b })
+					for _, v := range seq.data {
+						tree.Insert(v)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkRotate measures a single left and right rotation in isolation.
+// Each iteration rotates the root left and back right, restoring the original shape.
+func BenchmarkRotate(b *testing.B) {
+	tree := NewTree[int](func(a, b int) int { return a - b })
+	for _, v := range balancedOrder(1000) {
+		tree.Insert(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.leftRotate(tree.root)
+		tree.rightRotate(tree.root)
+	}
+}
+
+// BenchmarkSortedLoad compares loading keys in non-decreasing order
+// with and without the sorted-append mode
+func BenchmarkSortedLoad(b *testing.B) {
+	modes := []struct {
+		name string
+		opts []Option[int]
+	}{
+		{"default", nil},
+		{"sorted_append", []Option[int]{WithSortedAppend[int]()}},
+	}
+
+	benchmarks := []struct {
+		name string
+		size int
+	}{
+		{"1000_elements", 1000},
+		{"100000_elements", 100000},
+	}
+
+	for _, bm := range benchmarks {
+		for _, mode := range modes {
+			b.Run(mode.name+"/"+bm.name, func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					tree := NewTree[int](func(a, b int) int { return a - b }, mode.opts...)
+					for v := 0; v < bm.size; v++ {
+						tree.Insert(v)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkRankDuplicates measures Rank on trees where 90% of the keys are copies
+// of a few values, compared with a tree of mostly distinct keys of the same size
+func BenchmarkRankDuplicates(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		size int
+	}{
+		{"1000_elements", 1000},
+		{"100000_elements", 100000},
+	}
+
+	for _, bm := range benchmarks {
+		distinct := generateRandomData(bm.size)
+		duplicates := make([]int, bm.size)
+		for i := range duplicates {
+			if i%10 == 0 {
+				duplicates[i] = randGen.Intn(bm.size * 10)
+			} else {
+				duplicates[i] = randGen.Intn(10) * bm.size
+			}
+		}
+
+		datasets := []struct {
+			name string
+			data []int
+		}{
+			{"distinct", distinct},
+			{"duplicates", duplicates},
+		}
+
+		for _, ds := range datasets {
+			tree := NewTree[int](func(a, b int) int { return a - b })
+			for _, v := range ds.data {
+				tree.Insert(v)
+			}
+
+			b.Run(ds.name+"/"+bm.name, func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for j := 0; j < 100; j++ {
+						tree.Rank(ds.data[randGen.Intn(len(ds.data))])
+					}
+				}
+			})
+		}
+	}
+}
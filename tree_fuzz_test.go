@@ -1,6 +1,8 @@
 package gostree
 
 import (
+	"maps"
+	"slices"
 	"testing"
 )
 
@@ -205,3 +207,156 @@ func inOrderTraversal[T any](tree *Tree[T], node, nil *Node[T], values *[]T) {
 	*values = append(*values, node.key)
 	inOrderTraversal(tree, node.right, nil, values)
 }
+
+// Set operation codes for fuzzing
+const (
+	setOpAddFrom byte = iota
+	setOpRetainAll
+	setOpUnionAll
+	setOpIntersectionCount
+	setOpUnionStable
+	setOpDiff
+	setOpMergeIter
+	setOpCount
+)
+
+// FuzzSetOps builds two trees from random data, applies a set operation
+// and checks the result against a reference computed with maps
+func FuzzSetOps(f *testing.F) {
+	f.Add(setOpAddFrom, []byte{0, 1, 1, 2, 0, 3, 1, 3})
+	f.Add(setOpRetainAll, []byte{0, 5, 0, 5, 0, 6, 1, 5, 1, 7})
+	f.Add(setOpUnionAll, []byte{0, 1, 0, 2, 0, 3})
+	f.Add(setOpIntersectionCount, []byte{0, 4, 0, 4, 1, 4, 1, 4, 1, 4, 1, 9})
+	f.Add(setOpRetainAll, []byte{1, 1, 1, 2})
+	f.Add(setOpAddFrom, []byte{})
+	f.Add(setOpUnionStable, []byte{0, 2, 1, 2, 0, 2, 1, 1})
+	f.Add(setOpDiff, []byte{0, 3, 0, 3, 1, 3, 1, 4, 0, 5})
+	f.Add(setOpMergeIter, []byte{1, 8, 0, 8, 0, 1, 1, 9})
+
+	f.Fuzz(func(t *testing.T, op byte, data []byte) {
+		trees := [2]*Tree[int]{
+			NewTree[int](func(a, b int) int { return a - b }),
+			NewTree[int](func(a, b int) int { return a - b }),
+		}
+		counts := [2]map[int]int{{}, {}}
+
+		// Process data in pairs (target tree, value); values are folded
+		// into a small range so that duplicates and overlaps are common
+		for i := 0; i+1 < len(data); i += 2 {
+			which := data[i] % 2
+			value := int(data[i+1] % 32)
+			trees[which].Insert(value)
+			counts[which][value]++
+		}
+		a, b := trees[0], trees[1]
+		sizeA, sizeB := a.Size(), b.Size()
+
+		sum := make(map[int]int)
+		intersection := make(map[int]int)
+		for value, count := range counts[0] {
+			sum[value] += count
+			if other := counts[1][value]; other > 0 {
+				intersection[value] = min(count, other)
+			}
+		}
+		for value, count := range counts[1] {
+			sum[value] += count
+		}
+
+		switch op % setOpCount {
+		case setOpAddFrom:
+			a.AddFrom(b)
+			verifySetOpResult(t, a, sum)
+			verifySetOpResult(t, b, counts[1])
+		case setOpRetainAll:
+			removed := a.RetainAll(b)
+			verifySetOpResult(t, a, intersection)
+			verifySetOpResult(t, b, counts[1])
+			if want := sizeA - a.Size(); removed != want {
+				t.Fatalf("RetainAll() = %d, want %d", removed, want)
+			}
+		case setOpUnionAll:
+			verifySetOpResult(t, UnionAll(a, b), sum)
+			verifySetOpResult(t, a, counts[0])
+			verifySetOpResult(t, b, counts[1])
+		case setOpIntersectionCount:
+			want := 0
+			for _, count := range intersection {
+				want += count
+			}
+			if got := IntersectionCount(a, b); got != want {
+				t.Fatalf("IntersectionCount() = %d, want %d", got, want)
+			}
+			if got := IntersectionCount(b, a); got != want {
+				t.Fatalf("IntersectionCount() reversed = %d, want %d", got, want)
+			}
+			if a.Size() != sizeA || b.Size() != sizeB {
+				t.Fatalf("IntersectionCount() modified its inputs")
+			}
+		case setOpUnionStable:
+			verifySetOpResult(t, UnionStable(a, b), sum)
+			verifySetOpResult(t, a, counts[0])
+			verifySetOpResult(t, b, counts[1])
+		case setOpDiff:
+			wantAdded, wantRemoved := make(map[int]int), make(map[int]int)
+			for value := range sum {
+				switch diff := counts[1][value] - counts[0][value]; {
+				case diff > 0:
+					wantAdded[value] = diff
+				case diff < 0:
+					wantRemoved[value] = -diff
+				}
+			}
+			added, removed := Diff(a, b)
+			verifySortedMultiset(t, "Diff() added", added, wantAdded)
+			verifySortedMultiset(t, "Diff() removed", removed, wantRemoved)
+			verifySetOpResult(t, a, counts[0])
+			verifySetOpResult(t, b, counts[1])
+		case setOpMergeIter:
+			var merged []int
+			MergeIter(a, b)(func(value int) bool {
+				merged = append(merged, value)
+
+				return true
+			})
+			verifySortedMultiset(t, "MergeIter()", merged, sum)
+		}
+	})
+}
+
+// verifySetOpResult checks that the tree is valid and holds exactly the expected multiset
+func verifySetOpResult(t *testing.T, tree *Tree[int], expected map[int]int) {
+	t.Helper()
+
+	checkRedBlackProperties(t, tree)
+	verifyOrderStatisticProperties(t, tree, expected)
+	verifyTreeIntegrity(t, tree)
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if err := tree.CheckOrderStatistics(); err != nil {
+		t.Fatalf("CheckOrderStatistics() = %v", err)
+	}
+
+	for value, count := range expected {
+		if start, end := tree.Span(value); end-start != count {
+			t.Fatalf("value %d occurs %d times, want %d", value, end-start, count)
+		}
+	}
+}
+
+// verifySortedMultiset checks that values are in ascending order and hold exactly the expected multiset
+func verifySortedMultiset(t *testing.T, name string, values []int, expected map[int]int) {
+	t.Helper()
+
+	if !slices.IsSorted(values) {
+		t.Fatalf("%s = %v, not in ascending order", name, values)
+	}
+	counts := make(map[int]int)
+	for _, value := range values {
+		counts[value]++
+	}
+	if !maps.Equal(counts, expected) {
+		t.Fatalf("%s holds %v, want %v", name, counts, expected)
+	}
+}
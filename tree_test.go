@@ -1,6 +1,10 @@
 package gostree
 
 import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -1027,3 +1031,623 @@ func TestEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestSortedSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if snapshot := tree.SortedSnapshot(); len(snapshot) != 0 {
+			t.Errorf("SortedSnapshot() = %v, want empty", snapshot)
+		}
+	})
+
+	t.Run("returns_sorted_copy", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{30, 10, 50, 20, 10, 40})
+
+		snapshot := tree.SortedSnapshot()
+		expected := []int{10, 10, 20, 30, 40, 50}
+		if len(snapshot) != len(expected) {
+			t.Fatalf("len(SortedSnapshot()) = %d, want %d", len(snapshot), len(expected))
+		}
+		for i, want := range expected {
+			if snapshot[i] != want {
+				t.Errorf("snapshot[%d] = %d, want %d", i, snapshot[i], want)
+			}
+		}
+	})
+
+	t.Run("not_affected_by_later_mutations", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		snapshot := tree.SortedSnapshot()
+
+		for _, v := range snapshot {
+			tree.Delete(v)
+			tree.Insert(v * 10)
+		}
+
+		expected := []int{1, 2, 3}
+		for i, want := range expected {
+			if snapshot[i] != want {
+				t.Errorf("snapshot[%d] = %d, want %d", i, snapshot[i], want)
+			}
+		}
+
+		snapshot[0] = 99
+		if tree.Search(99) {
+			t.Error("modifying the snapshot should not affect the tree")
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if tree.Update(1, 2) {
+			t.Error("Update should return false for empty tree")
+		}
+		if size := tree.Size(); size != 0 {
+			t.Errorf("Size() = %d, want 0", size)
+		}
+	})
+
+	t.Run("missing_key", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30})
+		if tree.Update(15, 25) {
+			t.Error("Update should return false when old key is missing")
+		}
+		if tree.Search(25) {
+			t.Error("new key should not be inserted when old key is missing")
+		}
+		if size := tree.Size(); size != 3 {
+			t.Errorf("Size() = %d, want 3", size)
+		}
+	})
+
+	t.Run("repositions_element", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30, 40, 50})
+		if !tree.Update(10, 45) {
+			t.Fatal("Update should return true for existing key")
+		}
+
+		expected := []int{20, 30, 40, 45, 50}
+		for i, want := range expected {
+			got, ok := tree.Select(i)
+			if !ok || got != want {
+				t.Errorf("Select(%d) = %d, %v; want %d, true", i, got, ok, want)
+			}
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("equal_keys_is_noop", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{10, 20, 30})
+		if !tree.Update(20, 20) {
+			t.Fatal("Update should return true for existing key")
+		}
+
+		if size := tree.Size(); size != 3 {
+			t.Errorf("Size() = %d, want 3", size)
+		}
+		if rank := tree.Rank(20); rank != 1 {
+			t.Errorf("Rank(20) = %d, want 1", rank)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("updates_single_duplicate", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{5, 5, 5})
+		if !tree.Update(5, 1) {
+			t.Fatal("Update should return true for existing key")
+		}
+
+		expected := []int{1, 5, 5}
+		for i, want := range expected {
+			got, ok := tree.Select(i)
+			if !ok || got != want {
+				t.Errorf("Select(%d) = %d, %v; want %d, true", i, got, ok, want)
+			}
+		}
+	})
+}
+
+func TestDrain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if keys := tree.Drain(); len(keys) != 0 {
+			t.Errorf("Drain() = %v, want empty", keys)
+		}
+	})
+
+	t.Run("returns_sorted_and_empties_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{30, 10, 50, 20, 10, 40})
+		tree.Delete(50) // leaves the sentinel's parent pointing at a node
+
+		keys := tree.Drain()
+		expected := []int{10, 10, 20, 30, 40}
+		if len(keys) != len(expected) {
+			t.Fatalf("len(Drain()) = %d, want %d", len(keys), len(expected))
+		}
+		for i, want := range expected {
+			if keys[i] != want {
+				t.Errorf("keys[%d] = %d, want %d", i, keys[i], want)
+			}
+		}
+
+		if size := tree.Size(); size != 0 {
+			t.Errorf("Size() = %d, want 0", size)
+		}
+		if tree.root != tree.nil || tree.nil.parent != tree.nil {
+			t.Error("tree should not reference any nodes after Drain")
+		}
+	})
+
+	t.Run("tree_usable_after_drain", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		tree.Drain()
+
+		tree.Insert(5)
+		tree.Insert(4)
+		if size := tree.Size(); size != 2 {
+			t.Errorf("Size() = %d, want 2", size)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+}
+
+func TestDeleteRankRange(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		i, j            int
+		expected        []int
+		expectedRemoved int
+	}{
+		{"bottom_slice", 0, 3, []int{4, 5, 6, 7, 8, 9, 10}, 3},
+		{"top_slice", 7, 10, []int{1, 2, 3, 4, 5, 6, 7}, 3},
+		{"middle_slice", 3, 6, []int{1, 2, 3, 7, 8, 9, 10}, 3},
+		{"everything", 0, 10, []int{}, 10},
+		{"clamped_bounds", -5, 100, []int{}, 10},
+		{"clamped_lower_bound", -5, 2, []int{3, 4, 5, 6, 7, 8, 9, 10}, 2},
+		{"empty_range", 4, 4, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0},
+		{"inverted_range", 6, 2, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0},
+		{"out_of_range", 10, 20, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree([]int{5, 3, 8, 1, 4, 7, 9, 2, 6, 10})
+
+			if removed := tree.DeleteRankRange(tc.i, tc.j); removed != tc.expectedRemoved {
+				t.Errorf("DeleteRankRange(%d, %d) = %d, want %d", tc.i, tc.j, removed, tc.expectedRemoved)
+			}
+
+			got := tree.SortedSnapshot()
+			if len(got) != len(tc.expected) {
+				t.Fatalf("elements = %v, want %v", got, tc.expected)
+			}
+			for k := range got {
+				if got[k] != tc.expected[k] {
+					t.Fatalf("elements = %v, want %v", got, tc.expected)
+				}
+			}
+
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		})
+	}
+
+	t.Run("large_tree_with_duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		for i := 0; i < 1000; i++ {
+			tree.Insert(i / 2)
+		}
+
+		// Drop the bottom 10% and the top 10%
+		tree.DeleteRankRange(900, 1000)
+		tree.DeleteRankRange(0, 100)
+
+		if size := tree.Size(); size != 800 {
+			t.Errorf("Size() = %d, want 800", size)
+		}
+		if first, _ := tree.Select(0); first != 50 {
+			t.Errorf("Select(0) = %d, want 50", first)
+		}
+		if last, _ := tree.Select(799); last != 449 {
+			t.Errorf("Select(799) = %d, want 449", last)
+		}
+
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+		verifyTreeIntegrity(t, tree)
+	})
+}
+
+func TestSwap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exchanges_contents", func(t *testing.T) {
+		t.Parallel()
+
+		a := buildTree([]int{1, 2, 3, 4, 5})
+		b := NewTree[int](func(a, b int) int { return b - a }) // descending
+		for _, v := range []int{10, 30, 20} {
+			b.Insert(v)
+		}
+
+		a.Swap(b)
+
+		if got, want := a.SortedSnapshot(), []int{30, 20, 10}; !slices.Equal(got, want) {
+			t.Errorf("a = %v, want %v", got, want)
+		}
+		if got, want := b.SortedSnapshot(), []int{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+			t.Errorf("b = %v, want %v", got, want)
+		}
+
+		a.Insert(25)
+		b.Insert(0)
+		for _, tree := range []*Tree[int]{a, b} {
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		}
+		if got, want := a.SortedSnapshot(), []int{30, 25, 20, 10}; !slices.Equal(got, want) {
+			t.Errorf("a after insert = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("with_empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		a := buildTree([]int{1, 2})
+		b := NewTree[int](func(a, b int) int { return a - b })
+
+		a.Swap(b)
+
+		if a.Size() != 0 || b.Size() != 2 {
+			t.Errorf("sizes = %d, %d, want 0, 2", a.Size(), b.Size())
+		}
+	})
+}
+
+func TestSizeOverflowGuard(t *testing.T) {
+	t.Parallel()
+
+	inserts := map[string]func(*Tree[int]){
+		"insert":           func(tree *Tree[int]) { tree.Insert(2) },
+		"insert_ascending": func(tree *Tree[int]) { _ = tree.InsertAscending(2) },
+	}
+
+	for name, insert := range inserts {
+		insert := insert
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree([]int{1})
+			tree.root.size = math.MaxInt // stub a full tree
+
+			defer func() {
+				r := recover()
+				if msg, ok := r.(string); !ok || !strings.Contains(msg, "overflow") {
+					t.Errorf("recover() = %v, want overflow panic", r)
+				}
+				if tree.root.size != math.MaxInt || tree.root.right != tree.nil {
+					t.Error("tree was modified before the guard fired")
+				}
+			}()
+			insert(tree)
+		})
+	}
+}
+
+func TestDeleteManyWithRanks(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		values    []int
+		keys      []int
+		wantRanks []int
+		remaining []int
+	}{
+		{
+			name:      "ranks_shift_with_deletion_order",
+			values:    []int{10, 20, 30, 40, 50},
+			keys:      []int{20, 40, 10},
+			wantRanks: []int{1, 2, 0},
+			remaining: []int{30, 50},
+		},
+		{
+			name:      "absent_keys",
+			values:    []int{10, 20, 30},
+			keys:      []int{15, 30, 30},
+			wantRanks: []int{-1, 2, -1},
+			remaining: []int{10, 20},
+		},
+		{
+			name:      "duplicates_removed_leftmost_first",
+			values:    []int{5, 7, 7, 7, 9},
+			keys:      []int{7, 9, 7},
+			wantRanks: []int{1, 3, 1},
+			remaining: []int{5, 7},
+		},
+		{
+			name:      "empty_batch",
+			values:    []int{1, 2},
+			keys:      nil,
+			wantRanks: []int{},
+			remaining: []int{1, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := buildTree(tc.values)
+			if got := tree.DeleteManyWithRanks(tc.keys); !slices.Equal(got, tc.wantRanks) {
+				t.Errorf("DeleteManyWithRanks(%v) = %v, want %v", tc.keys, got, tc.wantRanks)
+			}
+			if got := tree.SortedSnapshot(); !slices.Equal(got, tc.remaining) {
+				t.Errorf("elements = %v, want %v", got, tc.remaining)
+			}
+
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		})
+	}
+
+	t.Run("replays_on_slice", func(t *testing.T) {
+		t.Parallel()
+
+		values := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+		tree := buildTree(values)
+		list := tree.SortedSnapshot()
+
+		keys := []int{5, 1, 9, 3, 5, 8}
+		for i, rank := range tree.DeleteManyWithRanks(keys) {
+			if rank < 0 {
+				continue
+			}
+			if list[rank] != keys[i] {
+				t.Fatalf("rank %d holds %d, want %d", rank, list[rank], keys[i])
+			}
+			list = slices.Delete(list, rank, rank+1)
+		}
+
+		if got := tree.SortedSnapshot(); !slices.Equal(got, list) {
+			t.Errorf("tree = %v, replayed list = %v", got, list)
+		}
+	})
+}
+
+func TestDeleteRandom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		if _, ok := tree.DeleteRandom(rand.New(rand.NewSource(1))); ok {
+			t.Error("DeleteRandom() ok = true on empty tree, want false")
+		}
+	})
+
+	t.Run("drains_tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{5, 3, 8, 3, 1, 9, 7})
+		r := rand.New(rand.NewSource(2))
+		var removed []int
+		for tree.Size() > 0 {
+			key, ok := tree.DeleteRandom(r)
+			if !ok {
+				t.Fatal("DeleteRandom() ok = false on non-empty tree")
+			}
+			removed = append(removed, key)
+			checkRedBlackProperties(t, tree)
+			verifySizes(t, tree.root, tree.nil)
+		}
+
+		slices.Sort(removed)
+		if want := []int{1, 3, 3, 5, 7, 8, 9}; !slices.Equal(removed, want) {
+			t.Errorf("removed = %v, want %v", removed, want)
+		}
+	})
+
+	t.Run("uniform_distribution", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			elements = 10
+			trials   = 20000
+		)
+
+		tree := NewTree[int](func(a, b int) int { return a - b })
+		for i := 0; i < elements; i++ {
+			tree.Insert(i)
+		}
+
+		r := rand.New(rand.NewSource(3))
+		counts := make([]int, elements)
+		for i := 0; i < trials; i++ {
+			key, _ := tree.DeleteRandom(r)
+			counts[key]++
+			tree.Insert(key)
+		}
+
+		// Each element is expected trials/elements = 2000 times; allow 10%
+		for key, count := range counts {
+			if count < 1800 || count > 2200 {
+				t.Errorf("element %d removed %d times, want about %d", key, count, trials/elements)
+			}
+		}
+	})
+}
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("replaces_contents", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{100, 200, 300})
+		values := []int{9, 3, 7, 3, 1, 8, 2, 6, 5, 4}
+		tree.Reset(values)
+
+		if got, want := tree.SortedSnapshot(), []int{1, 2, 3, 3, 4, 5, 6, 7, 8, 9}; !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+		if tree.Size() != len(values) {
+			t.Errorf("Size() = %d, want %d", tree.Size(), len(values))
+		}
+		if values[0] != 9 || values[9] != 4 {
+			t.Error("Reset() modified the input slice")
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+
+		tree.Insert(0)
+		if first, _ := tree.Select(0); first != 0 {
+			t.Errorf("Select(0) after insert = %d, want 0", first)
+		}
+	})
+
+	t.Run("to_empty", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3})
+		tree.Reset(nil)
+		if tree.Size() != 0 {
+			t.Errorf("Size() = %d, want 0", tree.Size())
+		}
+	})
+
+	t.Run("stable_for_equal_keys", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct {
+			key, id int
+		}
+		tree := NewTree(func(a, b item) int { return a.key - b.key })
+		tree.Reset([]item{{2, 0}, {1, 1}, {2, 2}, {1, 3}})
+
+		want := []item{{1, 1}, {1, 3}, {2, 0}, {2, 2}}
+		if got := tree.SortedSnapshot(); !slices.Equal(got, want) {
+			t.Errorf("elements = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("bounded_tree", func(t *testing.T) {
+		t.Parallel()
+
+		smallest := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](3, EvictSmallest))
+		smallest.Reset([]int{5, 1, 4, 2, 3})
+		if got, want := smallest.SortedSnapshot(), []int{3, 4, 5}; !slices.Equal(got, want) {
+			t.Errorf("EvictSmallest elements = %v, want %v", got, want)
+		}
+
+		largest := NewTree[int](func(a, b int) int { return a - b }, WithMaxSize[int](3, EvictLargest))
+		largest.Reset([]int{5, 1, 4, 2, 3})
+		if got, want := largest.SortedSnapshot(), []int{1, 2, 3}; !slices.Equal(got, want) {
+			t.Errorf("EvictLargest elements = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestWalkAndPrune(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prune_evens", func(t *testing.T) {
+		t.Parallel()
+
+		values := make([]int, 0, 200)
+		for i := 0; i < 100; i++ {
+			values = append(values, i, 99-i)
+		}
+		tree := buildTree(values)
+
+		var visited []int
+		tree.WalkAndPrune(func(key int) (bool, bool) {
+			visited = append(visited, key)
+
+			return key%2 == 0, false
+		})
+
+		if len(visited) != 200 || !slices.IsSorted(visited) {
+			t.Errorf("visited %d keys, sorted = %v, want all 200 in order", len(visited), slices.IsSorted(visited))
+		}
+		for _, key := range tree.SortedSnapshot() {
+			if key%2 == 0 {
+				t.Fatalf("even key %d survived pruning", key)
+			}
+		}
+		if tree.Size() != 100 {
+			t.Errorf("Size() = %d, want 100", tree.Size())
+		}
+		checkRedBlackProperties(t, tree)
+		verifySizes(t, tree.root, tree.nil)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildTree([]int{1, 2, 3, 4, 5, 6})
+		tree.WalkAndPrune(func(key int) (bool, bool) {
+			return true, key == 3
+		})
+
+		if got, want := tree.SortedSnapshot(), []int{4, 5, 6}; !slices.Equal(got, want) {
+			t.Errorf("after WalkAndPrune = %v, want %v", got, want)
+		}
+		checkRedBlackProperties(t, tree)
+	})
+
+	t.Run("empty_tree", func(t *testing.T) {
+		t.Parallel()
+
+		buildTree(nil).WalkAndPrune(func(int) (bool, bool) {
+			t.Error("fn called on empty tree")
+
+			return false, false
+		})
+	})
+}
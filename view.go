@@ -0,0 +1,52 @@
+package gostree
+
+import "slices"
+
+// ReadOnlyView provides the order-statistic queries of Tree over an already sorted
+// slice without copying it or allocating any nodes. Select is O(1) and Rank and Search
+// use binary search in O(log n). The view does not support mutation.
+type ReadOnlyView[T any] struct {
+	sorted  []T
+	compare CompareFunc[T]
+}
+
+// NewReadOnlyView creates a view backed directly by sorted, which must be in ascending
+// order according to compare. The slice is not copied, so it must not be modified
+// while the view is in use.
+func NewReadOnlyView[T any](sorted []T, compare CompareFunc[T]) *ReadOnlyView[T] {
+	return &ReadOnlyView[T]{
+		sorted:  sorted,
+		compare: compare,
+	}
+}
+
+// Search checks if a key exists in the view.
+func (v *ReadOnlyView[T]) Search(key T) bool {
+	_, found := slices.BinarySearchFunc(v.sorted, key, v.compare)
+
+	return found
+}
+
+// Select returns the k-th smallest element (0-indexed).
+func (v *ReadOnlyView[T]) Select(k int) (T, bool) {
+	if k < 0 || k >= len(v.sorted) {
+		var zero T
+
+		return zero, false
+	}
+
+	return v.sorted[k], true
+}
+
+// Rank returns the number of elements less than the given key.
+// If there are duplicates of the key, it returns the rank of the leftmost occurrence.
+func (v *ReadOnlyView[T]) Rank(key T) int {
+	rank, _ := slices.BinarySearchFunc(v.sorted, key, v.compare)
+
+	return rank
+}
+
+// Size returns the number of elements in the view.
+func (v *ReadOnlyView[T]) Size() int {
+	return len(v.sorted)
+}
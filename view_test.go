@@ -0,0 +1,71 @@
+package gostree
+
+import (
+	"testing"
+)
+
+func TestReadOnlyView(t *testing.T) {
+	t.Parallel()
+
+	compare := func(a, b int) int { return a - b }
+
+	t.Run("empty_view", func(t *testing.T) {
+		t.Parallel()
+
+		view := NewReadOnlyView(nil, compare)
+		if view.Size() != 0 {
+			t.Errorf("Size() = %d, want 0", view.Size())
+		}
+		if view.Search(1) {
+			t.Error("Search should return false for empty view")
+		}
+		if _, ok := view.Select(0); ok {
+			t.Error("Select(0) should return false for empty view")
+		}
+		if rank := view.Rank(1); rank != 0 {
+			t.Errorf("Rank(1) = %d, want 0", rank)
+		}
+	})
+
+	t.Run("matches_tree", func(t *testing.T) {
+		t.Parallel()
+
+		values := []int{15, 6, 18, 3, 7, 17, 20, 2, 4, 13, 9, 7, 7, 18}
+		tree := buildTree(values)
+		view := NewReadOnlyView(tree.SortedSnapshot(), compare)
+
+		if view.Size() != tree.Size() {
+			t.Errorf("Size() = %d, want %d", view.Size(), tree.Size())
+		}
+
+		for k := -1; k <= tree.Size(); k++ {
+			got, gotOK := view.Select(k)
+			want, wantOK := tree.Select(k)
+			if got != want || gotOK != wantOK {
+				t.Errorf("Select(%d) = %d, %v; want %d, %v", k, got, gotOK, want, wantOK)
+			}
+		}
+
+		for key := 0; key <= 22; key++ {
+			if got, want := view.Rank(key), tree.Rank(key); got != want {
+				t.Errorf("Rank(%d) = %d, want %d", key, got, want)
+			}
+			if got, want := view.Search(key), tree.Search(key); got != want {
+				t.Errorf("Search(%d) = %v, want %v", key, got, want)
+			}
+		}
+	})
+
+	t.Run("does_not_copy", func(t *testing.T) {
+		t.Parallel()
+
+		sorted := []int{1, 2, 3}
+		view := NewReadOnlyView(sorted, compare)
+
+		sorted[1] = 2 // rewrite in place keeps the order
+		sorted[2] = 30
+		if got, _ := view.Select(2); got != 30 {
+			t.Errorf("Select(2) = %d, want 30 from backing slice", got)
+		}
+	})
+}